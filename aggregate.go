@@ -0,0 +1,127 @@
+package main
+
+import "strconv"
+
+// `Aggregate` defines a value that a tree maintains for every subtree, like the sum or the maximum
+// of all data in the subtree. With these values in place, `Tree.Aggregate` can answer questions
+// like "what is the sum of all data between values a and b" in O(log n) steps.
+//
+// `Combine` must be associative, and `Identity` must be neutral with respect to `Combine`.
+// (In other words, `Identity` and `Combine` form a monoid.) The aggregate of a subtree is
+// `Combine(Combine(left, n.Data), right)`, where `left` and `right` are the aggregates of
+// the child subtrees, or `Identity` for missing children.
+type Aggregate struct {
+	Identity string
+	Combine  func(a, b string) string
+}
+
+var (
+	// `IntSum` adds up all data that are integers. Other data count as 0.
+	IntSum = Aggregate{
+		Identity: "0",
+		Combine: func(a, b string) string {
+			x, _ := strconv.ParseInt(a, 10, 64)
+			y, _ := strconv.ParseInt(b, 10, 64)
+			return strconv.FormatInt(x+y, 10)
+		},
+	}
+	// `IntMax` returns the largest of all data that are integers, or "" if there are none.
+	IntMax = Aggregate{
+		Identity: "",
+		Combine: func(a, b string) string {
+			x, errx := strconv.ParseInt(a, 10, 64)
+			y, erry := strconv.ParseInt(b, 10, 64)
+			switch {
+			case errx != nil && erry != nil:
+				return ""
+			case errx != nil || (erry == nil && y > x):
+				return b
+			}
+			return a
+		},
+	}
+)
+
+// `WithAggregate` makes `t` maintain aggregate `a` for every subtree, and returns `t`.
+// The aggregates of existing nodes are calculated right away.
+//
+// Only `Tree.Insert` maintains the aggregates. `Node.Insert` knows nothing about the tree
+// and leaves them alone.
+func (t *Tree) WithAggregate(a Aggregate) *Tree {
+	t.aggregate = &a
+	var walk func(*Node)
+	walk = func(n *Node) {
+		if n == nil {
+			return
+		}
+		walk(n.Left)
+		walk(n.Right)
+		t.updateAggregate(n)
+	}
+	walk(t.Root)
+	return t
+}
+
+// `aggOf` returns the aggregate of the subtree at `n`.
+func (a *Aggregate) aggOf(n *Node) string {
+	if n == nil {
+		return a.Identity
+	}
+	return n.agg
+}
+
+// `updateAggregate` re-calculates the aggregate of `n` from its children. Like `recorder`, it works
+// with a nil receiver, and it does nothing if the tree has no `Aggregate`.
+func (t *Tree) updateAggregate(n *Node) {
+	if t == nil || t.aggregate == nil {
+		return
+	}
+	a := t.aggregate
+	n.agg = a.Combine(a.Combine(a.aggOf(n.Left), n.Data), a.aggOf(n.Right))
+}
+
+// `updateAggregates` re-calculates the aggregates along a path of nodes, from the bottom up.
+func (t *Tree) updateAggregates(path []*Node) {
+	if t == nil || t.aggregate == nil {
+		return
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		t.updateAggregate(path[i])
+	}
+}
+
+// `Aggregate` returns the aggregate over the data of all nodes whose value `v` satisfies
+// `lo <= v < hi`. An empty `hi` means there is no upper bound, as in `Range`.
+// If the tree has no `Aggregate`, `Aggregate` returns "" and false.
+func (t *Tree) Aggregate(lo, hi string) (string, bool) {
+	if t.aggregate == nil {
+		return "", false
+	}
+	var hip *string
+	if hi != "" {
+		hip = &hi
+	}
+	return t.Root.aggregate(t.aggregate, &lo, hip), true
+}
+
+// `aggregate` descends only along the two paths to the bounds. Every subtree that
+// lies completely within the bounds contributes its stored aggregate.
+func (n *Node) aggregate(a *Aggregate, lo, hi *string) string {
+	if n == nil {
+		return a.Identity
+	}
+	if lo == nil && hi == nil {
+		return n.agg
+	}
+	if lo != nil && n.Value < *lo {
+		return n.Right.aggregate(a, lo, hi)
+	}
+	if hi != nil && n.Value >= *hi {
+		return n.Left.aggregate(a, lo, hi)
+	}
+	// `n` is within the bounds, hence its left subtree is bounded only by `lo`
+	// and its right subtree only by `hi`.
+	l := n.Left.aggregate(a, lo, nil)
+	r := n.Right.aggregate(a, nil, hi)
+	return a.Combine(a.Combine(l, n.Data), r)
+}
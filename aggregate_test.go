@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestTree_Aggregate(t *testing.T) {
+	for _, p := range policies {
+		for _, a := range []struct {
+			name string
+			agg  Aggregate
+		}{{"sum", IntSum}, {"max", IntMax}} {
+			t.Run(p.String()+"/"+a.name, func(t *testing.T) {
+				tree := NewTree(p).WithAggregate(a.agg)
+				model := map[string]string{}
+				r := rand.New(rand.NewSource(1))
+				for i := 0; i < 1000; i++ {
+					// Few distinct keys, so that many inserts are updates.
+					k, d := strconv.Itoa(100+r.Intn(400)), strconv.Itoa(r.Intn(1000)-500)
+					tree.Insert(k, d)
+					model[k] = d
+				}
+				if err := tree.Validate(); err != nil {
+					t.Fatal(err)
+				}
+				keys := sortedKeys(model)
+				for i := 0; i < 200; i++ {
+					lo, hi := strconv.Itoa(90+r.Intn(420)), strconv.Itoa(90+r.Intn(420))
+					if i%20 == 0 {
+						hi = ""
+					}
+					want := a.agg.Identity
+					for _, k := range keys {
+						if k >= lo && (hi == "" || k < hi) {
+							want = a.agg.Combine(want, model[k])
+						}
+					}
+					if got, ok := tree.Aggregate(lo, hi); !ok || got != want {
+						t.Fatalf("Aggregate(%s, %s) = %s, want %s", lo, hi, got, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestTree_WithAggregate(t *testing.T) {
+	tree := &Tree{}
+	if _, ok := tree.Aggregate("", ""); ok {
+		t.Errorf("tree without aggregate returns an aggregate")
+	}
+	for i := 1; i <= 10; i++ {
+		tree.Insert(strconv.Itoa(i), strconv.Itoa(i))
+	}
+	// Aggregates for existing nodes are calculated when the aggregate is set.
+	tree.WithAggregate(IntSum)
+	if sum, _ := tree.Aggregate("", ""); sum != "55" {
+		t.Errorf("sum of 1..10 is %s, want 55", sum)
+	}
+	// "10" < "2" < ... < "5"
+	if sum, _ := tree.Aggregate("10", "5"); sum != "19" {
+		t.Errorf("sum of 10, 2, 3, 4 is %s, want 19", sum)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import "strconv"
+
+// `Bag` is a sorted multiset: it counts how often each value was added.
+//
+// A bag is a `Tree` whose data is the count of each value. An `IntSum` aggregate
+// adds up the counts of each subtree, so that `Rank` needs only O(log n) steps.
+//
+// The zero value is an empty bag that is ready to use.
+type Bag struct {
+	tree *Tree
+	size int
+}
+
+func (b *Bag) init() {
+	if b.tree == nil {
+		b.tree = (&Tree{}).WithAggregate(IntSum)
+	}
+}
+
+// `Add` adds `value` once and returns its new count.
+func (b *Bag) Add(value string) int {
+	return b.AddN(value, 1)
+}
+
+// `AddN` adds `value` n times and returns its new count.
+func (b *Bag) AddN(value string, n int) int {
+	if n <= 0 {
+		return b.Count(value)
+	}
+	b.init()
+	c := b.Count(value) + n
+	b.tree.Insert(value, strconv.Itoa(c))
+	b.size += n
+	return c
+}
+
+// `Remove` removes `value` once and returns its new count. If the count drops to zero,
+// the value is removed from the bag entirely. Removing a value that is not in the bag does nothing.
+func (b *Bag) Remove(value string) int {
+	c := b.Count(value)
+	switch c {
+	case 0:
+		return 0
+	case 1:
+		b.tree.Delete(value)
+	default:
+		b.tree.Insert(value, strconv.Itoa(c-1))
+	}
+	b.size--
+	return c - 1
+}
+
+// `Count` returns how often `value` is in the bag.
+func (b *Bag) Count(value string) int {
+	if b.tree == nil {
+		return 0
+	}
+	d, found := b.tree.Find(value)
+	if !found {
+		return 0
+	}
+	c, _ := strconv.Atoi(d)
+	return c
+}
+
+// `Len` returns the total number of values in the bag, counting duplicates.
+func (b *Bag) Len() int {
+	return b.size
+}
+
+// `Rank` returns the number of values in the bag that are smaller than `value`, counting duplicates.
+// For a leaderboard sorted in ascending order, this is the zero-based position of the first occurrence of `value`.
+func (b *Bag) Rank(value string) int {
+	if b.tree == nil || value == "" {
+		return 0
+	}
+	sum, _ := b.tree.Aggregate("", value)
+	r, _ := strconv.Atoi(sum)
+	return r
+}
+
+// `Walk` calls `f` for each distinct value and its count in sort order.
+func (b *Bag) Walk(f func(value string, count int)) {
+	if b.tree == nil {
+		return
+	}
+	b.tree.Walk(func(v, d string) {
+		c, _ := strconv.Atoi(d)
+		f(v, c)
+	})
+}
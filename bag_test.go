@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestBag(t *testing.T) {
+	b := &Bag{}
+	// The model maps each value to its count.
+	count := func(m map[string]string, k string) int {
+		c, _ := strconv.Atoi(m[k])
+		return c
+	}
+	add := modelOp{
+		name: "Add",
+		do:   func(k, _ string) string { return strconv.Itoa(b.Add(k)) },
+		model: func(m map[string]string, k, _ string) string {
+			m[k] = strconv.Itoa(count(m, k) + 1)
+			return m[k]
+		},
+	}
+	model := runModelTest(t, modelTest{
+		ops: []modelOp{add, add, {
+			name: "Remove",
+			do:   func(k, _ string) string { return strconv.Itoa(b.Remove(k)) },
+			model: func(m map[string]string, k, _ string) string {
+				switch c := count(m, k); c {
+				case 0:
+					return "0"
+				case 1:
+					delete(m, k)
+					return "0"
+				default:
+					m[k] = strconv.Itoa(c - 1)
+					return m[k]
+				}
+			},
+		}},
+		keys:  100,
+		steps: 3000,
+		check: func(tb testing.TB) {
+			if b.tree == nil {
+				return
+			}
+			if err := b.tree.Validate(); err != nil {
+				tb.Fatal(err)
+			}
+		},
+		walk: func(f func(k, d string)) {
+			b.Walk(func(v string, c int) { f(v, strconv.Itoa(c)) })
+		},
+	})
+
+	rank := 0
+	for _, k := range sortedKeys(model) {
+		if b.Count(k) != count(model, k) {
+			t.Fatalf("Count(%s) = %d, want %d", k, b.Count(k), count(model, k))
+		}
+		if b.Rank(k) != rank {
+			t.Fatalf("Rank(%s) = %d, want %d", k, b.Rank(k), rank)
+		}
+		rank += count(model, k)
+	}
+	if b.Len() != rank {
+		t.Fatalf("Len() = %d, want %d", b.Len(), rank)
+	}
+}
+
+func TestBag_zero(t *testing.T) {
+	b := &Bag{}
+	if b.Count("a") != 0 || b.Remove("a") != 0 || b.Rank("a") != 0 || b.Len() != 0 {
+		t.Errorf("empty bag is not empty")
+	}
+	b.Walk(func(string, int) { t.Errorf("empty bag yields a value") })
+	if b.AddN("a", 3) != 3 || b.Rank("b") != 3 {
+		t.Errorf("AddN does not count")
+	}
+}
@@ -101,7 +101,7 @@ After inserting or deleting a node, the balance factors of all affected nodes an
 
 Here is how `Insert` maintains the balance factors:
 
-1. First, `Insert` descends recursively down the tree until it finds a node `n` to append the new value. `n` is either a leaf (that is, it has no children) or a half-leaf (that is, it has exactly one (direct) child).
+1. First, `Insert` descends down the tree until it finds a node `n` to append the new value. (`Node.Insert` does this recursively; `Tree.Insert` does the same in a loop, see below.) `n` is either a leaf (that is, it has no children) or a half-leaf (that is, it has exactly one (direct) child).
 2. If `n` is a leaf, adding a new child node increases the height of the subtree `n` by 1. If the child node is added to the left, the balance of `n` changes from 0 to -1. If the child is added to the right, the balance changes from 0 to 1.
 2. `Insert` now adds a new child node to node `n`.
 3. The height increase is passed back to `n`'s parent node.
@@ -177,7 +177,10 @@ First, we set up two helper functions, `min` and `max`, that we will need later.
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 )
 
@@ -197,6 +200,10 @@ func max(a, b int) int {
 	return b
 }
 
+// `verbose` switches the debug output of `rebalance` and the rotation methods on or off.
+// It is off by default; only the demo in `main` turns it on.
+var verbose = false
+
 // `Node` gets a new field, `height`, to store the height of the subtree at this node.
 type Node struct {
 	Value  string
@@ -204,6 +211,8 @@ type Node struct {
 	Left   *Node
 	Right  *Node
 	height int
+	// `agg` is the aggregate over the `Data` of the subtree, if the tree has an `Aggregate`.
+	agg string
 }
 
 // Height returns the height value. Wait, what's the point?
@@ -249,6 +258,7 @@ func (n *Node) Insert(value, data string) *Node {
 	// Actually, this is Upsert semantics. ("Upsert" is a coinage made from "Update or Insert".)
 	// Alternatively, Insert could return an error here, and an extra
 	// Update method would be required for updating existing data.
+	// (`StrictTree` and `Tree.Update` do exactly this.)
 	if n.Value == value {
 		n.Data = data
 		return n
@@ -269,17 +279,24 @@ func (n *Node) Insert(value, data string) *Node {
 	n.height = max(n.Left.Height(), n.Right.Height()) + 1
 
 	// Also, the subtree at node `n` might be out of balance.
-	return n.rebalance()
+	// (A node knows nothing about its tree, so there is no `Tree` to pass here.
+	// Recording, counting rotations, and aggregates are up to `Tree.Insert`.)
+	return n.rebalance(nil)
 }
 
 /* ### The new `rebalance()` method and its helpers `rotateLeft()`, `rotateRight()`, `rotateLeftRight()`, and `rotateRightLeft`.
 
- **Important note: Many of the assumptions about balances, left and right children, etc, as well as much of the logic usde in the functions below, apply to the `Insert` operation only. For `Delete` operations, different rules and operations apply.** As noted earlier, this article focuses on `Insert` only, to keep the code short and clear.
+ **Important note: Many of the assumptions about balances, left and right children, etc, as well as much of the logic usde in the functions below, apply to the `Insert` operation only. For `Delete` operations, different rules and operations apply.** As noted earlier, this article focuses on `Insert` only, to keep the code short and clear. (The one extra case that `Delete` needs is marked in `rebalance`; the rest of `Delete` lives in delete.go.)
  */
 
 // `rotateLeft` rotates the node to the left.
-func (n *Node) rotateLeft() *Node {
-	fmt.Println("rotateLeft " + n.Value)
+func (n *Node) rotateLeft(t *Tree) *Node {
+	if verbose {
+		fmt.Println("rotateLeft " + n.Value)
+	}
+	// If the tree records its rotations, take a snapshot of the subtree now.
+	done := t.recorder().rotation("rotateLeft", n)
+	t.countRotation()
 	// Save `n`'s right child in `r`.
 	r := n.Right
 	// Move `r`'s right subtree to the left of n.
@@ -289,54 +306,78 @@ func (n *Node) rotateLeft() *Node {
 	// Finally, re-calculate the heights of n and r.
 	n.height = max(n.Left.Height(), n.Right.Height()) + 1
 	r.height = max(r.Left.Height(), r.Right.Height()) + 1
+	// If the tree keeps aggregates, these must be re-calculated in the same order.
+	t.updateAggregate(n)
+	t.updateAggregate(r)
+	done(r)
 	// Return the new top node of this part of the tree.
 	return r
 }
 
 // `rotateRight` is the mirrored version of `rotateLeft`.
-func (n *Node) rotateRight() *Node {
-	fmt.Println("rotateRight " + n.Value)
+func (n *Node) rotateRight(t *Tree) *Node {
+	if verbose {
+		fmt.Println("rotateRight " + n.Value)
+	}
+	done := t.recorder().rotation("rotateRight", n)
+	t.countRotation()
 	l := n.Left
 	n.Left = l.Right
 	l.Right = n
 	n.height = max(n.Left.Height(), n.Right.Height()) + 1
 	l.height = max(l.Left.Height(), l.Right.Height()) + 1
+	t.updateAggregate(n)
+	t.updateAggregate(l)
+	done(l)
 	return l
 }
 
 // `rotateRightLeft` first rotates the right child of `c` to the right, then `c` to the left.
-func (n *Node) rotateRightLeft() *Node {
-	n.Right = n.Right.rotateRight()
-	n = n.rotateLeft()
+func (n *Node) rotateRightLeft(t *Tree) *Node {
+	done := t.recorder().rotation("rotateRightLeft", n)
+	n.Right = n.Right.rotateRight(t)
+	n = n.rotateLeft(t)
 	n.height = max(n.Left.Height(), n.Right.Height()) + 1
+	done(n)
 	return n
 }
 
 // `rotateLeftRight` first rotates the left child of `c` to the left, then `c` to the right.
-func (n *Node) rotateLeftRight() *Node {
-	n.Left = n.Left.rotateLeft()
-	n = n.rotateRight()
+func (n *Node) rotateLeftRight(t *Tree) *Node {
+	done := t.recorder().rotation("rotateLeftRight", n)
+	n.Left = n.Left.rotateLeft(t)
+	n = n.rotateRight(t)
 	n.height = max(n.Left.Height(), n.Right.Height()) + 1
+	done(n)
 	return n
 }
 
 // `rebalance` brings the (sub-)tree with root node `c` back into a balanced state.
-func (n *Node) rebalance() *Node {
-	fmt.Println("rebalance " + n.Value)
-	n.Dump(0, "")
+// How much imbalance is tolerated depends on the tree's `Policy`; for `AVL`, `k` is 1.
+// (With a larger `k`, the left or right child can have a balance other than -1 or 1, so only the sign counts.)
+func (n *Node) rebalance(t *Tree) *Node {
+	if verbose {
+		fmt.Println("rebalance " + n.Value)
+		n.Dump(0, "")
+	}
+	k := t.Policy().maxBal()
+	if k < 0 {
+		return n
+	}
 	switch {
 	// Left subtree is too high, and left child has a left child.
-	case n.Bal() < -1 && n.Left.Bal() == -1:
-		return n.rotateRight()
+	// (After a delete, the left child can also have two equally high subtrees. A single rotation does the job then, too.)
+	case n.Bal() < -k && n.Left.Bal() <= 0:
+		return n.rotateRight(t)
 	// Right subtree is too high, and right child has a right child.
-	case n.Bal() > 1 && n.Right.Bal() == 1:
-		return n.rotateLeft()
+	case n.Bal() > k && n.Right.Bal() >= 0:
+		return n.rotateLeft(t)
 	// Left subtree is too high, and left child has a right child.
-	case n.Bal() < -1 && n.Left.Bal() == 1:
-		return n.rotateLeftRight()
+	case n.Bal() < -k && n.Left.Bal() > 0:
+		return n.rotateLeftRight(t)
 	// Right subtree is too high, and right child has a left child.
-	case n.Bal() > 1 && n.Right.Bal() == -1:
-		return n.rotateRightLeft()
+	case n.Bal() > k && n.Right.Bal() < 0:
+		return n.rotateRightLeft(t)
 	}
 	return n
 }
@@ -379,33 +420,49 @@ func (n *Node) Dump(i int, lr string) {
 
 Changes to the Tree type:
 
-* `Insert` now takes care of rebalancing the root node if necessary.
+* `Insert` walks down and back up the tree in a loop instead of calling the recursive `Node.Insert` (see iterative.go). On the way up, it calls `rebalance` only for a node that is out of balance, and it stops as soon as a subtree keeps its height, because then nothing above can change. If the walk gets as far as the root node, the root gets rebalanced like any other node, so `Tree` needs no `rebalance` method of its own.
 * A new method, `Dump`, exist for invoking `Node.Dump`.
-* `Delete` is gone.
+* `Delete` is back, in delete.go. It rebalances every node on the way up, as a delete can unbalance more than one ancestor. `rebalance` handles the one case that only a delete can cause: a child with a balance of 0.
 
 */
 
 type Tree struct {
 	Root *Node
+	// If `Recorder` is set, `Insert` records all rotations into it.
+	Recorder *Recorder
+	// `policy` decides how strictly the tree keeps its balance. See `NewTree`.
+	policy    Policy
+	rotations int
+	// `aggregate`, if set, is maintained for every subtree. See `WithAggregate`.
+	aggregate *Aggregate
 }
 
+// `Insert` does not call the recursive `Node.Insert` but walks down and up the tree in a loop.
+// See `insert` in iterative.go. The resulting tree is the same.
 func (t *Tree) Insert(value, data string) {
-	t.Root = t.Root.Insert(value, data)
-	// If the root node gets out of balance,
-	if t.Root.Bal() < -1 || t.Root.Bal() > 1 {
-		t.rebalance()
+	t.insertStep(value, data, upsert)
+}
+
+// `insertStep` calls `insert` as one step of the `Recorder`. If `mode` makes `insert`
+// leave the tree unchanged, there is no step.
+func (t *Tree) insertStep(value, data string, mode insertMode) (old string, found bool) {
+	t.Recorder.begin("insert", value, data)
+	old, found = t.insert(value, data, mode)
+	if (mode == addOnly && found) || (mode == replaceOnly && !found) {
+		t.Recorder.discard()
+		return old, found
 	}
+	t.Recorder.end(t.Root)
+	return old, found
 }
 
-// `Node`'s `rebalance` method is invoked from the parent node of the node that needs rebalancing.
-// However, the root node of a tree has no parent node.
-// Therefore, `Tree`'s `rebalance` method creates a fake parent node for rebalancing the root node.
-func (t *Tree) rebalance() {
-	if t == nil || t.Root == nil {
-		// Nothing to balance here.
-		return
+// `recorder` returns the tree's `Recorder`, or nil if there is none. Like `Node.Height`, it works
+// with a nil receiver, so that node methods can call it without checking whether they belong to a tree.
+func (t *Tree) recorder() *Recorder {
+	if t == nil {
+		return nil
 	}
-	t.Root = t.Root.rebalance()
+	return t.Recorder
 }
 
 // Find receives a value s and returns true if t contains s.
@@ -433,9 +490,14 @@ func (t *Tree) Traverse(n *Node, f func(*Node)) {
 // know the maximum length of all values of a given tree level
 // in advance, in order to format the tree properly.
 func (t *Tree) PrettyPrint() {
+	t.prettyPrint(os.Stdout)
+}
+
+// `prettyPrint` does the work for `PrettyPrint`, writing to `w`.
+func (t *Tree) prettyPrint(w io.Writer) {
 
 	printNode := func(n *Node, depth int) {
-		fmt.Printf("%s%s\n", strings.Repeat("  ", depth), n.Value)
+		fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", depth), n.Value)
 	}
 
 	// `walk` has to be declared explicitly. Otherwise the recursive
@@ -463,24 +525,45 @@ func (t *Tree) Dump() {
 
 Using the `Dump` method plus some `fmt.Print...` statements at relevant places, we can watch the code how it inserts new values, rebalancing the subtrees where necessary.
 
+As `Tree.Insert` stops walking up early and skips nodes that are in balance, the demo prints "rebalance" only for the three nodes that actually get rotated. (The recursive `Node.Insert` calls `rebalance` for every node on the path, and would print each of them.) Here is the output for inserting "j", where node "h" becomes unbalanced:
+
+```
+Insert j: juliett
+rebalance h
+h[2,3]
++R--i[1,2]
+    +R--j[0,1]
+rotateLeft h
+d[1,4]
++L--b[0,2]
+    +L--a[0,1]
+    +R--c[0,1]
++R--g[0,3]
+    +L--e[1,2]
+        +R--f[0,1]
+    +R--i[0,2]
+        +L--h[0,1]
+        +R--j[0,1]
+```
+
 The output of the final `Dump` call should look like this:
 
 ```
-g[1]
-+L--d[0]
-    +L--b[0]
-        +L--a[0]
-        +R--c[0]
-    +R--e[1]
-        +R--f[0]
-+R--i[1]
-    +L--h[0]
-    +R--k[0]
-        +L--j[0]
-        +R--l[0]
+g[0,4]
++L--d[0,3]
+    +L--b[0,2]
+        +L--a[0,1]
+        +R--c[0,1]
+    +R--e[1,2]
+        +R--f[0,1]
++R--i[1,3]
+    +L--h[0,1]
+    +R--k[0,2]
+        +L--j[0,1]
+        +R--l[0,1]
 ```
 
-The small letters are the search values. "L" and "R" denote if the child node is a left or a right child. The number in brackets is the balance factor.
+The small letters are the search values. "L" and "R" denote if the child node is a left or a right child. The numbers in brackets are the balance factor and the height.
 
 If everything works correctly, the `Traverse` method should finally print out the nodes in alphabetical sort order.
 */
@@ -490,6 +573,32 @@ func main() {
 	values := []string{"d", "b", "g", "g", "c", "e", "a", "h", "f", "i", "j", "l", "k"}
 	data := []string{"delta", "bravo", "golang", "golf", "charlie", "echo", "alpha", "hotel", "foxtrot", "india", "juliett", "lima", "kilo"}
 
+	// A subcommand such as `load` or `get` turns the demo into a command-line tool; see cli.go.
+	if len(os.Args) > 1 && isCommand(os.Args[1]) {
+		if err := runCommand(os.Args[1:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// With `-html <file>`, write an animation of the inserts instead.
+	// Any further arguments replace the demo values.
+	htmlFile := flag.String("html", "", "write an HTML animation of the inserts to `file`")
+	flag.Parse()
+	if *htmlFile != "" {
+		if flag.NArg() > 0 {
+			values, data = flag.Args(), nil
+		}
+		if err := writeHTMLFile(*htmlFile, values, data); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The demo shows each rebalancing step.
+	verbose = true
 	tree := &Tree{}
 	for i := 0; i < len(values); i++ {
 		fmt.Println("Insert " + values[i] + ": " + data[i])
@@ -520,7 +629,7 @@ The code is also available on the [Go Playground](https://play.golang.org/p/dd1Z
 
 ## Conclusion
 
-Keeping a binary search tree in balance is a bit more involved as it might seem at first. In this article, I have broken down the rebalancing to the bare minimum by removing the `Delete` operation entirely. If you want to dig deeper, here are a couple of useful readings:
+Keeping a binary search tree in balance is a bit more involved as it might seem at first. In this article, I have broken down the rebalancing to the bare minimum by leaving the `Delete` operation out of the article. (You can find it in delete.go.) If you want to dig deeper, here are a couple of useful readings:
 
 [Wikipedia on Tree Rotation](https://en.wikipedia.org/wiki/Tree_rotation): Richly illustrated, concise discussion of the rotation process.
 
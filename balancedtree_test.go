@@ -92,9 +92,34 @@ func (n *Node) checkHeight() (*Node, bool) {
 	return nil, true
 }
 
+// recAggregate folds the data of the subtree in sort order, without using the stored aggregates.
+func (n *Node) recAggregate(a *Aggregate) string {
+	acc := a.Identity
+	(&Tree{}).Traverse(n, func(m *Node) { acc = a.Combine(acc, m.Data) })
+	return acc
+}
+
+// checkAggregates is like checkHeight, for the aggregates.
+func (n *Node) checkAggregates(a *Aggregate) (*Node, bool) {
+	if n == nil {
+		return nil, true
+	}
+	if n.agg != n.recAggregate(a) {
+		return n, false
+	}
+	if node, ok := n.Left.checkAggregates(a); !ok {
+		return node, false
+	}
+	return n.Right.checkAggregates(a)
+}
+
 // A (sub-)tree is balanced if the heights of the two child subtrees of any node differ by at most one.
 func (n *Node) isBalanced() bool {
-	return n == nil || n.Right.recHeight()-n.Left.recHeight() <= 1
+	if n == nil {
+		return true
+	}
+	d := n.Right.recHeight() - n.Left.recHeight()
+	return d >= -1 && d <= 1 && n.Left.isBalanced() && n.Right.isBalanced()
 }
 
 func (n *Node) checkBalances() (problem string) {
@@ -118,19 +143,122 @@ func (t *Tree) containsAllElements(source tree) (string, bool) {
 	return "", true
 }
 
+// isSorted checks that all values in the left subtree of any node are smaller, and all values
+// in the right subtree are larger than the node's value. Comparing each node with its direct
+// children only is not enough, so the bounds lo and hi are passed down the recursion.
+// As a side effect, isSorted also detects duplicate values.
 func (t *Tree) isSorted() bool {
-	var sorted func(*Node) bool
-	sorted = func(n *Node) bool {
+	var sorted func(n *Node, lo, hi *string) bool
+	sorted = func(n *Node, lo, hi *string) bool {
 		if n == nil {
 			return true
 		}
-		if (n.Left != nil && n.Value < n.Left.Value) ||
-			(n.Right != nil && n.Value > n.Right.Value) {
+		if (lo != nil && n.Value <= *lo) || (hi != nil && n.Value >= *hi) {
 			return false
 		}
-		return sorted(n.Left) && sorted(n.Right)
+		return sorted(n.Left, lo, &n.Value) && sorted(n.Right, &n.Value, hi)
+	}
+	return sorted(t.Root, nil, nil)
+}
+
+// corruptTrees are hand-crafted trees that each violate one invariant.
+// kind and value name the violation that Validate must report.
+var corruptTrees = []struct {
+	name  string
+	tree  *Tree
+	kind  ViolationKind
+	value string
+}{
+	{
+		name: "wrongheight",
+		tree: &Tree{Root: &Node{Value: "b", height: 1,
+			Left: &Node{Value: "a", height: 1},
+		}},
+		kind:  WrongHeight,
+		value: "b",
+	},
+	{
+		name: "unbalanced",
+		tree: &Tree{Root: &Node{Value: "a", height: 3,
+			Right: &Node{Value: "b", height: 2,
+				Right: &Node{Value: "c", height: 1},
+			},
+		}},
+		kind:  Unbalanced,
+		value: "a",
+	},
+	{
+		name: "wrongchild",
+		tree: &Tree{Root: &Node{Value: "b", height: 2,
+			Left:  &Node{Value: "c", height: 1},
+			Right: &Node{Value: "d", height: 1},
+		}},
+		kind:  Unordered,
+		value: "c",
+	},
+	{
+		// "e" is larger than its parent "b" but must be smaller than its grandparent "d".
+		name: "wronggrandchild",
+		tree: &Tree{Root: &Node{Value: "d", height: 3,
+			Left: &Node{Value: "b", height: 2,
+				Left:  &Node{Value: "a", height: 1},
+				Right: &Node{Value: "e", height: 1},
+			},
+			Right: &Node{Value: "f", height: 1},
+		}},
+		kind:  Unordered,
+		value: "e",
+	},
+	{
+		name: "duplicate",
+		tree: &Tree{Root: &Node{Value: "b", height: 2,
+			Left:  &Node{Value: "a", height: 1},
+			Right: &Node{Value: "b", height: 1},
+		}},
+		kind:  Duplicate,
+		value: "b",
+	},
+	{
+		name: "wrongaggregate",
+		tree: &Tree{aggregate: &IntSum, Root: &Node{Value: "b", Data: "2", agg: "3", height: 2,
+			Left:  &Node{Value: "a", Data: "1", agg: "1", height: 1},
+			Right: &Node{Value: "c", Data: "3", agg: "3", height: 1},
+		}},
+		kind:  WrongAggregate,
+		value: "b",
+	},
+}
+
+// TestInvariantChecks verifies that the invariant checks used in TestTree_rebalance
+// actually detect broken trees.
+func TestInvariantChecks(t *testing.T) {
+	for _, c := range corruptTrees {
+		t.Run(c.name, func(t *testing.T) {
+			_, heightOK := c.tree.Root.checkHeight()
+			balanceOK := c.tree.Root.checkBalances() == "" && c.tree.Root.isBalanced()
+			sortedOK := c.tree.isSorted()
+			switch c.kind {
+			case WrongHeight:
+				if heightOK {
+					t.Errorf("checkHeight did not detect the wrong height")
+				}
+			case Unbalanced:
+				if balanceOK {
+					t.Errorf("isBalanced did not detect the imbalance")
+				}
+			case Unordered, Duplicate:
+				if sortedOK {
+					t.Errorf("isSorted did not detect the wrong order")
+				}
+			case WrongAggregate:
+				if n, ok := c.tree.Root.checkAggregates(c.tree.aggregate); ok || n.Value != c.value {
+					t.Errorf("checkAggregates did not detect the wrong aggregate")
+				}
+			default:
+				t.Errorf("no check for %s", c.kind)
+			}
+		})
 	}
-	return sorted(t.Root)
 }
 
 func TestTree_rebalance(t *testing.T) {
@@ -159,7 +287,7 @@ func TestTree_rebalance(t *testing.T) {
 			}
 
 			if !tt.isSorted() {
-				problem += fmt.Sprintf("Tree %s is not balanced\n", tree.name)
+				problem += fmt.Sprintf("Tree %s is not sorted\n", tree.name)
 			}
 
 			if n, ok := tt.Root.checkHeight(); !ok {
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"testing"
+)
+
+// Benchmarks compare Tree against a map and a sorted slice.
+// Run them with
+//
+//	go test -run xxx -bench . -benchmem
+//
+// Sizes above 1e5 are skipped with -short.
+
+var benchSizes = []int{1e3, 1e4, 1e5, 1e6, 1e7}
+
+// benchDists generate n keys in a given distribution.
+// Keys are zero-padded so that numeric and lexicographic order agree.
+var benchDists = []struct {
+	name string
+	keys func(n int) []string
+}{
+	{"random", func(n int) []string {
+		r := rand.New(rand.NewSource(1))
+		return benchKeys(n, func(i int) uint64 { return uint64(r.Int63()) })
+	}},
+	{"ascending", func(n int) []string {
+		return benchKeys(n, func(i int) uint64 { return uint64(i) })
+	}},
+	{"descending", func(n int) []string {
+		return benchKeys(n, func(i int) uint64 { return uint64(n - i) })
+	}},
+	{"zipf", func(n int) []string {
+		z := rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, uint64(n-1))
+		return benchKeys(n, func(int) uint64 { return z.Uint64() })
+	}},
+}
+
+func benchKeys(n int, next func(i int) uint64) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%020d", next(i))
+	}
+	return keys
+}
+
+// sortedSlice is an ordered map backed by two parallel slices and binary search.
+type sortedSlice struct {
+	keys []string
+	data []string
+}
+
+func (s *sortedSlice) Insert(key, data string) {
+	i := sort.SearchStrings(s.keys, key)
+	if i < len(s.keys) && s.keys[i] == key {
+		s.data[i] = data
+		return
+	}
+	s.keys = append(s.keys, "")
+	s.data = append(s.data, "")
+	copy(s.keys[i+1:], s.keys[i:])
+	copy(s.data[i+1:], s.data[i:])
+	s.keys[i] = key
+	s.data[i] = data
+}
+
+func (s *sortedSlice) Find(key string) (string, bool) {
+	i := sort.SearchStrings(s.keys, key)
+	if i < len(s.keys) && s.keys[i] == key {
+		return s.data[i], true
+	}
+	return "", false
+}
+
+// benchEach runs f for each combination of distribution and size.
+func benchEach(b *testing.B, f func(b *testing.B, keys []string)) {
+	for _, d := range benchDists {
+		for _, n := range benchSizes {
+			if testing.Short() && n > 1e5 {
+				continue
+			}
+			d, n := d, n
+			b.Run(fmt.Sprintf("%s/%d", d.name, n), func(b *testing.B) { f(b, d.keys(n)) })
+		}
+	}
+}
+
+// Each op inserts one key. After len(keys) inserts, the structure starts over empty.
+func BenchmarkInsert(b *testing.B) {
+	benchEach(b, func(b *testing.B, keys []string) {
+		b.Run("Tree", func(b *testing.B) {
+			b.ReportAllocs()
+			t := &Tree{}
+			for i := 0; i < b.N; i++ {
+				if i%len(keys) == 0 {
+					t = &Tree{}
+				}
+				t.Insert(keys[i%len(keys)], "")
+			}
+		})
+		b.Run("map", func(b *testing.B) {
+			b.ReportAllocs()
+			m := map[string]string{}
+			for i := 0; i < b.N; i++ {
+				if i%len(keys) == 0 {
+					m = map[string]string{}
+				}
+				m[keys[i%len(keys)]] = ""
+			}
+		})
+		b.Run("slice", func(b *testing.B) {
+			// Inserting into the middle of a slice is O(n); larger sizes take far too long.
+			if len(keys) > 1e5 {
+				b.Skip("too slow")
+			}
+			b.ReportAllocs()
+			s := &sortedSlice{}
+			for i := 0; i < b.N; i++ {
+				if i%len(keys) == 0 {
+					s = &sortedSlice{}
+				}
+				s.Insert(keys[i%len(keys)], "")
+			}
+		})
+	})
+}
+
+// Each op looks up one key in a structure that contains all keys.
+func BenchmarkFind(b *testing.B) {
+	benchEach(b, func(b *testing.B, keys []string) {
+		t := &Tree{}
+		m := map[string]string{}
+		s := &sortedSlice{}
+		for _, k := range keys {
+			t.Insert(k, k)
+			m[k] = k
+		}
+		s.keys = sortedKeys(m)
+		s.data = s.keys
+		// Look up the keys in a different order than they were inserted.
+		lookup := make([]string, len(keys))
+		copy(lookup, keys)
+		rand.New(rand.NewSource(2)).Shuffle(len(lookup), func(i, j int) { lookup[i], lookup[j] = lookup[j], lookup[i] })
+
+		b.Run("Tree", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				t.Find(lookup[i%len(lookup)])
+			}
+		})
+		b.Run("map", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = m[lookup[i%len(lookup)]]
+			}
+		})
+		b.Run("slice", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s.Find(lookup[i%len(lookup)])
+			}
+		})
+	})
+}
+
+// Each op visits all keys in sort order. A map has to sort its keys first.
+func BenchmarkTraverse(b *testing.B) {
+	benchEach(b, func(b *testing.B, keys []string) {
+		t := &Tree{}
+		m := map[string]string{}
+		for _, k := range keys {
+			t.Insert(k, k)
+			m[k] = k
+		}
+		s := &sortedSlice{keys: sortedKeys(m)}
+		s.data = s.keys
+		var sink string
+
+		b.Run("Tree", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				t.Traverse(t.Root, func(n *Node) { sink = n.Data })
+			}
+		})
+		b.Run("map", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, k := range sortedKeys(m) {
+					sink = m[k]
+				}
+			}
+		})
+		b.Run("slice", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for j := range s.keys {
+					sink = s.data[j]
+				}
+			}
+		})
+		_ = sink
+	})
+}
+
+// Tree.Insert walks the tree iteratively, Node.Insert recursively.
+// Both descend the same way, so the difference is in the walk back up. With random keys,
+// the cache misses of the descent dominate; with ascending keys, the descent is cheap.
+func BenchmarkInsertPath(b *testing.B) {
+	for _, dist := range benchDists[:2] {
+		for _, n := range benchSizes {
+			if testing.Short() && n > 1e5 {
+				continue
+			}
+			n := n
+			b.Run(fmt.Sprintf("%s/%d", dist.name, n), func(b *testing.B) {
+				keys := dist.keys(n)
+				b.Run("iterative", func(b *testing.B) {
+					b.ReportAllocs()
+					t := &Tree{}
+					for i := 0; i < b.N; i++ {
+						if i%n == 0 {
+							t = &Tree{}
+						}
+						t.Insert(keys[i%n], "")
+					}
+				})
+				b.Run("recursive", func(b *testing.B) {
+					b.ReportAllocs()
+					t := &Tree{}
+					for i := 0; i < b.N; i++ {
+						if i%n == 0 {
+							t = &Tree{}
+						}
+						t.Root = t.Root.Insert(keys[i%n], "")
+					}
+				})
+			})
+		}
+	}
+}
+
+// Each op is a full garbage collection with a large tree on the heap.
+func BenchmarkGC(b *testing.B) {
+	keys := benchDists[0].keys(1e6)
+	b.Run("Tree", func(b *testing.B) {
+		t := &Tree{}
+		for _, k := range keys {
+			t.Insert(k, k)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			runtime.GC()
+		}
+		runtime.KeepAlive(t)
+	})
+	b.Run("SlabTree", func(b *testing.B) {
+		t := NewSlabTree(len(keys))
+		for _, k := range keys {
+			t.Insert(k, k)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			runtime.GC()
+		}
+		runtime.KeepAlive(t)
+	})
+}
+
+// Each op inserts one key into each OrderedMap implementation.
+func BenchmarkOrderedMapInsert(b *testing.B) {
+	benchEach(b, func(b *testing.B, keys []string) {
+		for _, om := range orderedMaps {
+			om := om
+			b.Run(om.name, func(b *testing.B) {
+				b.ReportAllocs()
+				m := om.new()
+				for i := 0; i < b.N; i++ {
+					if i%len(keys) == 0 {
+						m = om.new()
+					}
+					m.Insert(keys[i%len(keys)], "")
+				}
+			})
+		}
+	})
+}
+
+// Each op looks up one key. In the skewed workload, lookups follow a Zipf distribution
+// over the stored keys; in the uniform workload, all keys are equally likely.
+func BenchmarkSplayFind(b *testing.B) {
+	const n = 1e5
+	keys := benchDists[0].keys(n)
+	r := rand.New(rand.NewSource(3))
+	z := rand.NewZipf(r, 1.1, 1, n-1)
+	workloads := map[string][]string{"skewed": make([]string, n), "uniform": make([]string, n)}
+	for i := 0; i < n; i++ {
+		workloads["skewed"][i] = keys[z.Uint64()]
+		workloads["uniform"][i] = keys[r.Intn(n)]
+	}
+	for _, w := range []string{"skewed", "uniform"} {
+		lookup := workloads[w]
+		for _, name := range []string{"Tree", "SplayTree"} {
+			var om OrderedMap = &Tree{}
+			if name == "SplayTree" {
+				om = &SplayTree{}
+			}
+			for _, k := range keys {
+				om.Insert(k, k)
+			}
+			b.Run(w+"/"+name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					om.Find(lookup[i%n])
+				}
+			})
+		}
+	}
+}
+
+// Each op inserts one random key. Besides time, the benchmark reports the rotations per insert
+// and the final height of the tree for each policy.
+func BenchmarkPolicy(b *testing.B) {
+	keys := benchDists[0].keys(1e5)
+	for _, p := range []Policy{AVL, RelaxedAVL(2), RelaxedAVL(3), NoBalancing} {
+		p := p
+		b.Run(p.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			t := NewTree(p)
+			rotations, height := 0, 0
+			for i := 0; i < b.N; i++ {
+				if i%len(keys) == 0 {
+					rotations += t.Stats().Rotations
+					t = NewTree(p)
+				}
+				t.Insert(keys[i%len(keys)], "")
+			}
+			rotations += t.Stats().Rotations
+			height = t.Stats().Height
+			b.ReportMetric(float64(rotations)/float64(b.N), "rotations/op")
+			b.ReportMetric(float64(height), "height")
+		})
+	}
+}
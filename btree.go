@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// `BTree` is an in-memory B-tree.
+//
+// A B-tree node holds many values instead of just one, and has one more child than values.
+// All leaves are on the same level, so the tree is always perfectly balanced in terms of height.
+// Because each node stores its values in contiguous slices, a search touches far fewer
+// cache lines than in a binary tree with the same number of values.
+//
+// The degree `d` controls the node size: every node except the root holds between d-1
+// and 2d-1 values.
+type BTree struct {
+	root   *bnode
+	degree int
+}
+
+type bnode struct {
+	values   []string
+	data     []string
+	children []*bnode // empty for leaves
+}
+
+// `NewBTree` creates an empty B-tree with the given degree. The minimum degree is 2.
+func NewBTree(degree int) *BTree {
+	if degree < 2 {
+		degree = 2
+	}
+	return &BTree{degree: degree}
+}
+
+func (n *bnode) leaf() bool {
+	return len(n.children) == 0
+}
+
+// `search` returns the position of `value` in `n.values` and whether it is there.
+// If it is not, the position is the child to descend into.
+func (n *bnode) search(value string) (int, bool) {
+	i := sort.SearchStrings(n.values, value)
+	return i, i < len(n.values) && n.values[i] == value
+}
+
+// `Find` returns the data stored for `value`.
+func (t *BTree) Find(value string) (string, bool) {
+	n := t.root
+	for n != nil {
+		i, found := n.search(value)
+		if found {
+			return n.data[i], true
+		}
+		if n.leaf() {
+			break
+		}
+		n = n.children[i]
+	}
+	return "", false
+}
+
+// `splitChild` splits the full child `i` of `n` into two nodes and moves its middle value up into `n`.
+func (n *bnode) splitChild(i, degree int) {
+	c := n.children[i]
+	mid := degree - 1
+	right := &bnode{
+		values: append([]string(nil), c.values[mid+1:]...),
+		data:   append([]string(nil), c.data[mid+1:]...),
+	}
+	if !c.leaf() {
+		right.children = append([]*bnode(nil), c.children[mid+1:]...)
+		c.children = c.children[:mid+1]
+	}
+	value, data := c.values[mid], c.data[mid]
+	c.values, c.data = c.values[:mid], c.data[:mid]
+
+	n.values = insertString(n.values, i, value)
+	n.data = insertString(n.data, i, data)
+	n.children = append(n.children, nil)
+	copy(n.children[i+2:], n.children[i+1:])
+	n.children[i+1] = right
+}
+
+func insertString(s []string, i int, v string) []string {
+	s = append(s, "")
+	copy(s[i+1:], s[i:])
+	s[i] = v
+	return s
+}
+
+// `Insert` inserts a value or updates the data of an existing one, like `Tree.Insert`.
+//
+// On the way down, `Insert` splits every full node it meets. This way, there is always room
+// in the parent for the middle value of a split, and no second pass up the tree is needed.
+func (t *BTree) Insert(value, data string) {
+	if t.degree == 0 {
+		t.degree = 2
+	}
+	if t.root == nil {
+		t.root = &bnode{}
+	}
+	if len(t.root.values) == 2*t.degree-1 {
+		// The tree grows at the top, not at the leaves.
+		t.root = &bnode{children: []*bnode{t.root}}
+		t.root.splitChild(0, t.degree)
+	}
+	n := t.root
+	for {
+		i, found := n.search(value)
+		if found {
+			n.data[i] = data
+			return
+		}
+		if n.leaf() {
+			n.values = insertString(n.values, i, value)
+			n.data = insertString(n.data, i, data)
+			return
+		}
+		if len(n.children[i].values) == 2*t.degree-1 {
+			n.splitChild(i, t.degree)
+			// The middle value of the child is now at position i.
+			switch {
+			case value == n.values[i]:
+				n.data[i] = data
+				return
+			case value > n.values[i]:
+				i++
+			}
+		}
+		n = n.children[i]
+	}
+}
+
+// `Range` calls `f` for each value `v` with `from <= v < to` in sort order, until `f` returns false.
+// An empty `to` means there is no upper bound.
+func (t *BTree) Range(from, to string, f func(value, data string) bool) {
+	t.root.walkRange(from, to, f)
+}
+
+func (n *bnode) walkRange(from, to string, f func(value, data string) bool) bool {
+	if n == nil {
+		return true
+	}
+	i, _ := n.search(from)
+	for ; i <= len(n.values); i++ {
+		if !n.leaf() && !n.children[i].walkRange(from, to, f) {
+			return false
+		}
+		if i == len(n.values) {
+			break
+		}
+		if to != "" && n.values[i] >= to {
+			return false
+		}
+		if !f(n.values[i], n.data[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// `Walk` calls `f` for each value and its data in sort order.
+func (t *BTree) Walk(f func(value, data string)) {
+	t.Range("", "", func(v, d string) bool {
+		f(v, d)
+		return true
+	})
+}
+
+// `Dump` prints one node per line, indented by level.
+func (t *BTree) Dump() {
+	var dump func(*bnode, int)
+	dump = func(n *bnode, i int) {
+		if n == nil {
+			return
+		}
+		fmt.Printf("%s%s\n", strings.Repeat("    ", i), strings.Join(n.values, " "))
+		for _, c := range n.children {
+			dump(c, i+1)
+		}
+	}
+	dump(t.root, 0)
+}
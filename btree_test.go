@@ -0,0 +1,92 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// checkBTree verifies that all leaves of the subtree at n are on the same level,
+// that all nodes have the allowed number of values, and that the values are sorted.
+// It returns the height of the subtree.
+func (n *bnode) checkBTree(tb testing.TB, degree int, root bool, lo, hi *string) int {
+	if !root && (len(n.values) < degree-1 || len(n.values) > 2*degree-1) {
+		tb.Fatalf("node %v has %d values", n.values, len(n.values))
+	}
+	for i, v := range n.values {
+		if (lo != nil && v <= *lo) || (hi != nil && v >= *hi) || (i > 0 && v <= n.values[i-1]) {
+			tb.Fatalf("node %v is not sorted", n.values)
+		}
+	}
+	if n.leaf() {
+		return 1
+	}
+	if len(n.children) != len(n.values)+1 {
+		tb.Fatalf("node %v has %d children", n.values, len(n.children))
+	}
+	h := -1
+	for i, c := range n.children {
+		clo, chi := lo, hi
+		if i > 0 {
+			clo = &n.values[i-1]
+		}
+		if i < len(n.values) {
+			chi = &n.values[i]
+		}
+		ch := c.checkBTree(tb, degree, false, clo, chi)
+		if h >= 0 && ch != h {
+			tb.Fatalf("leaves below %v are on different levels", n.values)
+		}
+		h = ch
+	}
+	return h + 1
+}
+
+// TestBTree cross-validates BTree against Tree with random inserts, lookups, and ranges.
+func TestBTree(t *testing.T) {
+	for _, degree := range []int{2, 3, 16} {
+		t.Run(strconv.Itoa(degree), func(t *testing.T) {
+			bt := NewBTree(degree)
+			tree := &Tree{}
+			r := rand.New(rand.NewSource(int64(degree)))
+			for i := 0; i < 3000; i++ {
+				k := strconv.Itoa(r.Intn(1000))
+				bt.Insert(k, strconv.Itoa(i))
+				tree.Insert(k, strconv.Itoa(i))
+			}
+			bt.root.checkBTree(t, degree, true, nil, nil)
+
+			for i := 0; i < 1000; i++ {
+				k := strconv.Itoa(i)
+				d, found := bt.Find(k)
+				if td, tfound := tree.Find(k); d != td || found != tfound {
+					t.Fatalf("Find(%s) = %s, %t; Tree has %s, %t", k, d, found, td, tfound)
+				}
+			}
+
+			for i := 0; i < 100; i++ {
+				from, to := strconv.Itoa(r.Intn(1000)), strconv.Itoa(r.Intn(1000))
+				if i%10 == 0 {
+					to = ""
+				}
+				var want, got []string
+				tree.Range(from, to, func(n *Node) bool {
+					want = append(want, n.Value+":"+n.Data)
+					return len(want) < 50
+				})
+				bt.Range(from, to, func(v, d string) bool {
+					got = append(got, v+":"+d)
+					return len(got) < 50
+				})
+				if len(got) != len(want) {
+					t.Fatalf("Range(%s, %s) yields %d values, Tree yields %d", from, to, len(got), len(want))
+				}
+				for j := range want {
+					if got[j] != want[j] {
+						t.Fatalf("Range(%s, %s) yields %s at position %d, Tree yields %s", from, to, got[j], j, want[j])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The subcommands turn the demo binary into a command-line tool for building and querying trees.
+// All subcommands except `load` work on a snapshot file written by `load`.
+//
+//	balancedtree load [-db file] [-format csv|tsv|jsonl] [-header csv,tsv] input...
+//	balancedtree get [-db file] key...
+//	balancedtree range [-db file] [-limit n] from [to]
+//	balancedtree dump [-db file]
+//	balancedtree dot [-db file]
+//	balancedtree stats [-db file]
+//	balancedtree validate [-db file]
+//	balancedtree repl [-db file] [-script file]
+//	balancedtree serve [-db file] [-addr host:port]
+//	balancedtree resp [-db file] [-addr host:port]
+var commands = map[string]func(fs *flag.FlagSet, db *string, args []string, w io.Writer) error{
+	"load":     cmdLoad,
+	"get":      cmdGet,
+	"range":    cmdRange,
+	"dump":     cmdDump,
+	"dot":      cmdDot,
+	"stats":    cmdStats,
+	"validate": cmdValidate,
+	"repl":     cmdREPL,
+	"serve":    cmdServe,
+	"resp":     cmdRESP,
+}
+
+// `errUsage` is returned for invalid command lines. The flag set has printed the usage already.
+var errUsage = errors.New("invalid arguments")
+
+const defaultDB = "tree.json"
+
+// `isCommand` tells whether `name` is a subcommand.
+func isCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// `runCommand` runs the subcommand `args[0]` with the remaining arguments and writes its output to `w`.
+func runCommand(args []string, w io.Writer) error {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %s", args[0])
+	}
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	db := fs.String("db", defaultDB, "snapshot `file`")
+	return cmd(fs, db, args[1:], w)
+}
+
+func cmdLoad(fs *flag.FlagSet, db *string, args []string, w io.Writer) error {
+	format := fs.String("format", "", "input format: csv, tsv, or jsonl (default: from the file extension)")
+	header := fs.String("header", "", "comma-separated `formats` whose files start with a header line: csv, tsv, or both")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	// Files of different formats often come from different sources, so the header setting is per format.
+	hasHeader := map[string]bool{}
+	for _, h := range strings.Split(*header, ",") {
+		switch h {
+		case "":
+		case "csv", "tsv":
+			hasHeader[h] = true
+		default:
+			return fmt.Errorf("load: -header %s: expected csv, tsv, or csv,tsv", h)
+		}
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("load: no input files")
+	}
+	t := &Tree{}
+	for _, name := range fs.Args() {
+		f := *format
+		if f == "" {
+			f = strings.TrimPrefix(filepath.Ext(name), ".")
+		}
+		n, err := loadFile(t, name, f, hasHeader[f])
+		if err != nil {
+			return fmt.Errorf("load %s: %w", name, err)
+		}
+		fmt.Fprintf(w, "%s: %d records\n", name, n)
+	}
+	return saveSnapshot(t, *db)
+}
+
+// `loadFile` inserts all key-value pairs from a file into `t` and returns their number.
+func loadFile(t *Tree, name, format string, header bool) (int, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	switch format {
+	case "csv", "tsv":
+		return loadCSV(t, f, format == "tsv", header)
+	case "jsonl", "ndjson":
+		return loadJSONL(t, f)
+	}
+	return 0, fmt.Errorf("unknown format %q", format)
+}
+
+// `loadCSV` reads records with the key in the first and the value in the second column.
+// Further columns are ignored.
+func loadCSV(t *Tree, r io.Reader, tabs, header bool) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	if tabs {
+		cr.Comma = '\t'
+		cr.LazyQuotes = true
+	}
+	n := 0
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if header && line == 1 {
+			continue
+		}
+		if len(rec) < 2 {
+			return n, fmt.Errorf("line %d: expected key and value, got %d fields", line, len(rec))
+		}
+		t.Insert(rec[0], rec[1])
+		n++
+	}
+}
+
+// `loadJSONL` reads one JSON object per line, with the fields "key" and "value".
+func loadJSONL(t *Tree, r io.Reader) (int, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	n := 0
+	for line := 1; s.Scan(); line++ {
+		if strings.TrimSpace(s.Text()) == "" {
+			continue
+		}
+		var rec struct {
+			Key   *string `json:"key"`
+			Value string  `json:"value"`
+		}
+		if err := json.Unmarshal(s.Bytes(), &rec); err != nil {
+			return n, fmt.Errorf("line %d: %w", line, err)
+		}
+		if rec.Key == nil {
+			return n, fmt.Errorf("line %d: no key", line)
+		}
+		t.Insert(*rec.Key, rec.Value)
+		n++
+	}
+	return n, s.Err()
+}
+
+// `openDB` parses the flags of a subcommand that reads a snapshot, and loads the snapshot.
+func openDB(fs *flag.FlagSet, db *string, args []string) (*Tree, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	return loadSnapshot(*db)
+}
+
+// `openOptionalDB` loads the snapshot only if the `-db` flag was given, and returns an empty tree otherwise.
+// `fs` must be parsed already.
+func openOptionalDB(fs *flag.FlagSet, db *string) (*Tree, error) {
+	set := false
+	fs.Visit(func(f *flag.Flag) { set = set || f.Name == "db" })
+	if !set {
+		return &Tree{}, nil
+	}
+	return loadSnapshot(*db)
+}
+
+func cmdGet(fs *flag.FlagSet, db *string, args []string, w io.Writer) error {
+	t, err := openDB(fs, db, args)
+	if err != nil {
+		return err
+	}
+	missing := 0
+	for _, k := range fs.Args() {
+		if d, found := t.Find(k); found {
+			fmt.Fprintf(w, "%s\t%s\n", k, d)
+		} else {
+			missing++
+		}
+	}
+	if missing > 0 {
+		return fmt.Errorf("get: %d of %d keys not found", missing, fs.NArg())
+	}
+	return nil
+}
+
+func cmdRange(fs *flag.FlagSet, db *string, args []string, w io.Writer) error {
+	limit := fs.Int("limit", 0, "print at most `n` records (0: no limit)")
+	t, err := openDB(fs, db, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return fmt.Errorf("range: expected from and optional to")
+	}
+	// `to` is exclusive. Without `to`, the range extends to the end.
+	from, to := fs.Arg(0), fs.Arg(1)
+	n := 0
+	t.Range(from, to, func(node *Node) bool {
+		fmt.Fprintf(w, "%s\t%s\n", node.Value, node.Data)
+		n++
+		return *limit <= 0 || n < *limit
+	})
+	return nil
+}
+
+func cmdDump(fs *flag.FlagSet, db *string, args []string, w io.Writer) error {
+	t, err := openDB(fs, db, args)
+	if err != nil {
+		return err
+	}
+	t.Root.snapshot().Dump(w, 0, "")
+	return nil
+}
+
+func cmdDot(fs *flag.FlagSet, db *string, args []string, w io.Writer) error {
+	t, err := openDB(fs, db, args)
+	if err != nil {
+		return err
+	}
+	return t.WriteDOT(w)
+}
+
+func cmdStats(fs *flag.FlagSet, db *string, args []string, w io.Writer) error {
+	t, err := openDB(fs, db, args)
+	if err != nil {
+		return err
+	}
+	n := t.Len()
+	fmt.Fprintf(w, "nodes:\t%d\n", n)
+	fmt.Fprintf(w, "height:\t%d\n", t.Root.Height())
+	// The lowest possible height for n nodes, for comparison.
+	fmt.Fprintf(w, "minimum height:\t%d\n", int(math.Ceil(math.Log2(float64(n)+1))))
+	if n > 0 {
+		first, last := t.Root, t.Root
+		for first.Left != nil {
+			first = first.Left
+		}
+		for last.Right != nil {
+			last = last.Right
+		}
+		fmt.Fprintf(w, "first key:\t%s\n", first.Value)
+		fmt.Fprintf(w, "last key:\t%s\n", last.Value)
+	}
+	return nil
+}
+
+func cmdValidate(fs *flag.FlagSet, db *string, args []string, w io.Writer) error {
+	// `ReadSnapshot` validates the tree already.
+	if _, err := openDB(fs, db, args); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "ok")
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// `runCLI` runs a subcommand against the snapshot `db` and returns its output.
+func runCLI(t *testing.T, db string, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	args = append([]string{args[0], "-db", db}, args[1:]...)
+	err := runCommand(args, &out)
+	return out.String(), err
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestCLI(t *testing.T) {
+	dir := t.TempDir()
+	db := filepath.Join(dir, "tree.json")
+	csvFile := writeFile(t, dir, "a.csv", "key,value\nd,delta\nb,\"bravo, too\"\n")
+	tsvFile := writeFile(t, dir, "b.tsv", "a\talpha\tignored\n")
+	jsonlFile := writeFile(t, dir, "c.jsonl", `{"key":"c","value":"charlie"}`+"\n\n"+`{"key":"e","value":"echo"}`+"\n")
+
+	if _, err := runCLI(t, db, "load", "-header", csvFile, tsvFile); err == nil {
+		t.Errorf("load accepted a file name as -header")
+	}
+	// The CSV file has a header line, the TSV file has none.
+	out, err := runCLI(t, db, "load", "-header", "csv", csvFile, tsvFile, jsonlFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "a.csv: 2 records") || !strings.Contains(out, "b.tsv: 1 records") || !strings.Contains(out, "c.jsonl: 2 records") {
+		t.Errorf("load printed:\n%s", out)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"get", "b", "e"}, "b\tbravo, too\ne\techo\n"},
+		{[]string{"range", "b", "e"}, "b\tbravo, too\nc\tcharlie\nd\tdelta\n"},
+		{[]string{"range", "-limit", "2", "c"}, "c\tcharlie\nd\tdelta\n"},
+		{[]string{"stats"}, "nodes:\t5\nheight:\t3\nminimum height:\t3\nfirst key:\ta\nlast key:\te\n"},
+		{[]string{"validate"}, "ok\n"},
+	}
+	for _, tt := range tests {
+		out, err := runCLI(t, db, tt.args...)
+		if err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+		}
+		if out != tt.want {
+			t.Errorf("%v printed %q, want %q", tt.args, out, tt.want)
+		}
+	}
+
+	out, _ = runCLI(t, db, "dump")
+	if !strings.HasPrefix(out, "b[1,3]\n+L--a[0,1]\n") {
+		t.Errorf("dump printed:\n%s", out)
+	}
+	out, _ = runCLI(t, db, "dot")
+	if !strings.HasPrefix(out, "digraph tree {") || strings.Count(out, " -> ") != 4 {
+		t.Errorf("dot printed:\n%s", out)
+	}
+
+	if _, err := runCLI(t, db, "get", "x"); err == nil {
+		t.Errorf("get of a missing key did not fail")
+	}
+	if _, err := runCLI(t, filepath.Join(dir, "missing.json"), "stats"); err == nil {
+		t.Errorf("stats without a snapshot did not fail")
+	}
+	bad := writeFile(t, dir, "bad.csv", "onlykey\n")
+	if _, err := runCLI(t, db, "load", bad); err == nil {
+		t.Errorf("load of a record without value did not fail")
+	}
+}
+
+func TestCLIDamagedSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	db := writeFile(t, dir, "tree.json", `{"version":1,"size":2,"root":{"value":"b","height":2,"right":{"value":"a","height":1}}}`)
+	if _, err := runCLI(t, db, "validate"); err == nil || !strings.Contains(err.Error(), "unordered") {
+		t.Errorf("validate returned %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+// The article leaves out `Delete` for brevity. Here it is.
+//
+// Deleting a node can make its parent's subtree one level lower, which in turn can throw
+// any ancestor out of balance. So, unlike after an insert, rebalancing might be necessary at
+// every level on the way back up, and not just once.
+
+// `Delete` removes `value` from the tree. It returns false if there was no such value.
+func (t *Tree) Delete(value string) bool {
+	t.Recorder.begin("delete", value, "")
+	deleted := false
+	t.Root = t.Root.delete(t, value, &deleted)
+	if !deleted {
+		t.Recorder.discard()
+		return false
+	}
+	t.Recorder.end(t.Root)
+	return true
+}
+
+// `delete` removes `value` from the subtree at `n` and returns the new top node of the subtree.
+func (n *Node) delete(t *Tree, value string, deleted *bool) *Node {
+	if n == nil {
+		return nil
+	}
+	switch {
+	case value < n.Value:
+		n.Left = n.Left.delete(t, value, deleted)
+	case value > n.Value:
+		n.Right = n.Right.delete(t, value, deleted)
+	default:
+		*deleted = true
+		// With at most one child, the child simply takes the node's place.
+		if n.Left == nil {
+			return n.Right
+		}
+		if n.Right == nil {
+			return n.Left
+		}
+		// With two children, the node takes over the value and data of its successor,
+		// that is, the leftmost node of its right subtree. Then the successor gets deleted instead.
+		s := n.Right
+		for s.Left != nil {
+			s = s.Left
+		}
+		n.Value, n.Data = s.Value, s.Data
+		n.Right = n.Right.delete(t, s.Value, deleted)
+	}
+	n.height = max(n.Left.Height(), n.Right.Height()) + 1
+	t.updateAggregate(n)
+	return n.rebalance(t)
+}
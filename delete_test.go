@@ -0,0 +1,106 @@
+package main
+
+import "testing"
+
+func TestTree_Delete(t *testing.T) {
+	for _, p := range policies {
+		t.Run(p.String(), func(t *testing.T) {
+			tree := NewTree(p).WithAggregate(IntSum)
+			runModelTest(t, modelTest{
+				ops:   mapOps(tree.Insert, tree.Delete),
+				keys:  300,
+				steps: 3000,
+				check: func(tb testing.TB) {
+					if err := tree.Validate(); err != nil {
+						tb.Fatal(err)
+					}
+				},
+				walk: tree.Walk,
+			})
+		})
+	}
+}
+
+func TestTree_DeleteRecorded(t *testing.T) {
+	rec := &Recorder{}
+	tree := &Tree{Recorder: rec}
+	for _, v := range []string{"b", "a", "c", "d"} {
+		tree.Insert(v, v)
+	}
+	// Deleting "a" leaves "b" with a right subtree of height 2.
+	tree.Delete("a")
+	last := rec.Steps[len(rec.Steps)-1]
+	if last.Op != "delete" || len(last.Rotations) != 1 || last.Rotations[0].Kind != "rotateLeft" {
+		t.Errorf("expected a recorded delete with one left rotation, got %+v", last)
+	}
+	n := len(rec.Steps)
+	tree.Delete("a")
+	if len(rec.Steps) != n {
+		t.Errorf("deleting a missing value was recorded")
+	}
+}
+
+// After a delete, the child on the higher side of an unbalanced node can have a balance of 0.
+// An insert never leaves such a child, so `TestTree_rebalance` does not cover these cases.
+// A single rotation must fix them.
+func TestRebalanceChildWithBalance0(t *testing.T) {
+	leaf := func(v string) *Node { return &Node{Value: v, Data: v, height: 1} }
+	tests := []struct {
+		name string
+		n    *Node
+		want string
+	}{
+		{
+			// d is left-heavy, and its left child b has two equally high subtrees.
+			name: "left",
+			n: &Node{Value: "d", Data: "d", height: 3,
+				Left: &Node{Value: "b", Data: "b", height: 2, Left: leaf("a"), Right: leaf("c")},
+			},
+			want: "b",
+		},
+		{
+			name: "right",
+			n: &Node{Value: "b", Data: "b", height: 3,
+				Right: &Node{Value: "d", Data: "d", height: 2, Left: leaf("c"), Right: leaf("e")},
+			},
+			want: "d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := &Tree{}
+			tree.Root = tt.n.rebalance(tree)
+			if tree.Root.Value != tt.want {
+				t.Errorf("root is %s after rebalancing, want %s", tree.Root.Value, tt.want)
+			}
+			if err := tree.Validate(); err != nil {
+				t.Error(err)
+			}
+			if s := tree.Stats(); s.Rotations != 1 {
+				t.Errorf("rebalance did %d rotations, want 1", s.Rotations)
+			}
+		})
+	}
+
+	// The same cases, caused by an actual delete.
+	for _, tt := range []struct {
+		values []string
+		del    string
+	}{
+		{[]string{"b", "a", "d", "c", "e"}, "a"},
+		{[]string{"d", "e", "b", "a", "c"}, "e"},
+	} {
+		tree := &Tree{}
+		for _, v := range tt.values {
+			tree.Insert(v, v)
+		}
+		before := tree.Stats().Rotations
+		tree.Delete(tt.del)
+		if err := tree.Validate(); err != nil {
+			t.Errorf("after deleting %s: %v", tt.del, err)
+		}
+		if r := tree.Stats().Rotations - before; r != 1 {
+			t.Errorf("deleting %s did %d rotations, want 1", tt.del, r)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// `WriteDOT` writes the tree in the DOT language of Graphviz. Render it with, for example,
+//
+//	dot -Tpng tree.dot > tree.png
+//
+// Each node is labeled with its value, balance, and height, as in `Dump`.
+// Missing left children are drawn as invisible nodes, so that a single right child
+// still appears on the right.
+func (t *Tree) WriteDOT(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "digraph tree {\n\tnode [shape=circle];"); err != nil {
+		return err
+	}
+	id := 0
+	var walk func(n *Node) (int, error)
+	walk = func(n *Node) (int, error) {
+		me := id
+		id++
+		if _, err := fmt.Fprintf(w, "\tn%d [label=%q];\n", me, fmt.Sprintf("%s\n[%d,%d]", n.Value, n.Bal(), n.Height())); err != nil {
+			return 0, err
+		}
+		for _, c := range []*Node{n.Left, n.Right} {
+			if c == nil {
+				if n.Left == nil && n.Right == nil {
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "\tn%d [style=invis];\n\tn%d -> n%d [style=invis];\n", id, me, id); err != nil {
+					return 0, err
+				}
+				id++
+				continue
+			}
+			child, err := walk(c)
+			if err != nil {
+				return 0, err
+			}
+			if _, err := fmt.Fprintf(w, "\tn%d -> n%d;\n", me, child); err != nil {
+				return 0, err
+			}
+		}
+		return me, nil
+	}
+	if t.Root != nil {
+		if _, err := walk(t.Root); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// `dumpTree` writes the subtree at `n` to `w` in the format of `Node.Dump`, for any kind of
+// binary tree node. `node` returns the text to print for a node, and its children.
+// `i` and `lr` are the indent level and the child prefix, as for `Node.Dump`.
+func dumpTree[N comparable](w io.Writer, n N, i int, lr string, node func(N) (text string, left, right N)) {
+	var none N
+	if n == none {
+		return
+	}
+	indent := ""
+	if i > 0 {
+		indent = strings.Repeat(" ", (i-1)*4) + "+" + lr + "--"
+	}
+	text, left, right := node(n)
+	fmt.Fprintf(w, "%s%s\n", indent, text)
+	dumpTree(w, left, i+1, "L", node)
+	dumpTree(w, right, i+1, "R", node)
+}
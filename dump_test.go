@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDumpTree(t *testing.T) {
+	sg := &ScapegoatTree{}
+	for _, v := range []string{"b", "a", "d", "c"} {
+		sg.Insert(v, "")
+	}
+	var out strings.Builder
+	dumpTree(&out, sg.Root, 0, "", func(n *ScapegoatNode) (string, *ScapegoatNode, *ScapegoatNode) {
+		return n.Value, n.Left, n.Right
+	})
+	if want := "b\n+L--a\n+R--d\n    +L--c\n"; out.String() != want {
+		t.Errorf("dumpTree wrote %q, want %q", out.String(), want)
+	}
+	out.Reset()
+	dumpTree(&out, (*ScapegoatNode)(nil), 0, "", nil)
+	if out.Len() != 0 {
+		t.Errorf("dumpTree wrote %q for an empty tree", out.String())
+	}
+}
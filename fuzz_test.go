@@ -0,0 +1,92 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// Operations that FuzzTree decodes from its input.
+const (
+	opInsert = iota
+	opUpsert
+	opFind
+	numOps
+)
+
+// FuzzTree decodes the input into a sequence of operations and applies them
+// to a Tree and to a map. Each operation takes three bytes: the operation,
+// the key (or, for opUpsert, the index of an existing key), and the data.
+// After each step, the Tree must contain exactly what the map contains,
+// in sort order, and satisfy all invariants.
+func FuzzTree(f *testing.F) {
+	for _, tree := range trees {
+		var seed []byte
+		for i := range tree.value {
+			// Fixture values are single characters or digits.
+			seed = append(seed, opInsert, tree.value[i][0], byte(i))
+		}
+		f.Add(seed)
+	}
+	f.Add([]byte{opInsert, 1, 1, opUpsert, 0, 2, opFind, 1, 0, opFind, 2, 0})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		tree := &Tree{}
+		model := map[string]string{}
+		for i := 0; i+2 < len(ops); i += 3 {
+			op, key, data := ops[i]%numOps, strconv.Itoa(int(ops[i+1])), strconv.Itoa(int(ops[i+2]))
+			switch op {
+			case opInsert:
+				tree.Insert(key, data)
+				model[key] = data
+			case opUpsert:
+				if len(model) == 0 {
+					continue
+				}
+				key = sortedKeys(model)[int(ops[i+1])%len(model)]
+				tree.Insert(key, data)
+				model[key] = data
+			case opFind:
+				d, found := tree.Find(key)
+				md, mfound := model[key]
+				if found != mfound || d != md {
+					t.Fatalf("step %d: Find(%s) = %s, %t; want %s, %t", i/3, key, d, found, md, mfound)
+				}
+			}
+			checkAgainstModel(t, tree, model)
+		}
+	})
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkAgainstModel verifies that tree and model have the same contents.
+func checkAgainstModel(t *testing.T, tree *Tree, model map[string]string) {
+	t.Helper()
+	keys := sortedKeys(model)
+	i := 0
+	tree.Traverse(tree.Root, func(n *Node) {
+		if i >= len(keys) || n.Value != keys[i] || n.Data != model[keys[i]] {
+			t.Fatalf("traversal yields %s: %s at position %d; want keys %v", n.Value, n.Data, i, keys)
+		}
+		i++
+	})
+	if i != len(keys) {
+		t.Fatalf("traversal yields %d nodes, want %d", i, len(keys))
+	}
+	for _, k := range keys {
+		if d, found := tree.Find(k); !found || d != model[k] {
+			t.Fatalf("Find(%s) = %s, %t; want %s, true", k, d, found, model[k])
+		}
+	}
+	if err := tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// `IntervalTree` stores closed intervals [Lo, Hi] and finds all intervals that overlap
+// a given point or interval.
+//
+// It is a `Tree` whose values are the intervals, ordered by start (and by end, to tell apart
+// intervals that start at the same point). An `Aggregate` maintains the largest end point
+// of every subtree. A search can then skip every subtree whose largest end point lies before
+// the point or interval in question.
+//
+// Like `Tree`, `IntervalTree` compares strings. For time windows, use a format that sorts
+// lexicographically, like RFC 3339 timestamps in UTC, or zero-padded numbers.
+// End points must not contain the byte 0, which separates the parts of a node's value and data.
+//
+// The zero value is an empty tree that is ready to use.
+type IntervalTree struct {
+	tree Tree
+	size int
+}
+
+// `Interval` is a closed interval with some data attached.
+type Interval struct {
+	Lo, Hi string
+	Data   string
+}
+
+// `less` orders intervals by start, then by end.
+func (iv Interval) less(o Interval) bool {
+	return iv.Lo < o.Lo || (iv.Lo == o.Lo && iv.Hi < o.Hi)
+}
+
+// A node stores an interval as follows:
+//
+//	Value: Lo + "\x00" + Hi
+//	Data:  Hi + "\x00" + Data
+//
+// As "\x00" sorts before any other byte, the values sort like `Interval.less`. The end point at the
+// start of `Data` lets the aggregate find the largest end point without looking at the value.
+const intervalSep = "\x00"
+
+func intervalValue(lo, hi string) string {
+	return lo + intervalSep + hi
+}
+
+// `interval` decodes the interval stored in `n`.
+func (n *Node) interval() Interval {
+	lo, hi := splitAtSep(n.Value)
+	_, data := splitAtSep(n.Data)
+	return Interval{Lo: lo, Hi: hi, Data: data}
+}
+
+// `splitAtSep` splits `s` at the first separator. Aggregates contain no separator and come back whole.
+func splitAtSep(s string) (before, after string) {
+	if i := strings.Index(s, intervalSep); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// `maxHi` is the aggregate of an `IntervalTree`. It combines node data and aggregates
+// alike, by comparing the end points in front of the separator.
+var maxHi = Aggregate{
+	Identity: "",
+	Combine: func(a, b string) string {
+		a, _ = splitAtSep(a)
+		b, _ = splitAtSep(b)
+		if b > a {
+			return b
+		}
+		return a
+	},
+}
+
+// `init` sets up the aggregate of a zero `IntervalTree`.
+func (t *IntervalTree) init() {
+	if t.tree.aggregate == nil {
+		t.tree.WithAggregate(maxHi)
+	}
+}
+
+// `Insert` adds the interval [lo, hi] with the given data. If the tree contains
+// this interval already, `Insert` only replaces the data.
+func (t *IntervalTree) Insert(lo, hi, data string) error {
+	if hi < lo {
+		return fmt.Errorf("invalid interval [%s, %s]: end is before start", lo, hi)
+	}
+	if strings.Contains(lo, intervalSep) || strings.Contains(hi, intervalSep) {
+		return fmt.Errorf("invalid interval [%q, %q]: end points must not contain the byte 0", lo, hi)
+	}
+	t.init()
+	if _, replaced := t.tree.Upsert(intervalValue(lo, hi), hi+intervalSep+data); !replaced {
+		t.size++
+	}
+	return nil
+}
+
+// `Len` returns the number of intervals in the tree.
+func (t *IntervalTree) Len() int {
+	return t.size
+}
+
+// `Stabbing` returns all intervals that contain `point`, ordered by start.
+func (t *IntervalTree) Stabbing(point string) []Interval {
+	return t.Overlapping(point, point)
+}
+
+// `Overlapping` returns all intervals that overlap [lo, hi], ordered by start.
+func (t *IntervalTree) Overlapping(lo, hi string) []Interval {
+	var result []Interval
+	t.tree.Root.overlapping(lo, hi, &result)
+	return result
+}
+
+func (n *Node) overlapping(lo, hi string, result *[]Interval) {
+	// No interval in this subtree reaches as far as `lo`.
+	if n == nil || n.agg < lo {
+		return
+	}
+	n.Left.overlapping(lo, hi, result)
+	iv := n.interval()
+	// This interval and all intervals in the right subtree start after `hi`.
+	if iv.Lo > hi {
+		return
+	}
+	if iv.Hi >= lo {
+		*result = append(*result, iv)
+	}
+	n.Right.overlapping(lo, hi, result)
+}
+
+// `Walk` calls `f` for each interval, ordered by start.
+func (t *IntervalTree) Walk(f func(Interval)) {
+	t.tree.Traverse(t.tree.Root, func(n *Node) { f(n.interval()) })
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// checkIntervals verifies the order and the stored end points of the subtree at n.
+// As in isSorted, lo and hi bound the intervals of the subtree, and are passed down the recursion.
+// It returns the largest end point in the subtree.
+func checkIntervals(tb testing.TB, n *Node, lo, hi *Interval) string {
+	if n == nil {
+		return ""
+	}
+	iv := n.interval()
+	if (lo != nil && !lo.less(iv)) || (hi != nil && !iv.less(*hi)) {
+		tb.Fatalf("node %v is out of order", iv)
+	}
+	maxHi := iv.Hi
+	for _, h := range []string{checkIntervals(tb, n.Left, lo, &iv), checkIntervals(tb, n.Right, &iv, hi)} {
+		if h > maxHi {
+			maxHi = h
+		}
+	}
+	if n.agg != maxHi {
+		tb.Fatalf("node %v has maxHi %s, want %s", iv, n.agg, maxHi)
+	}
+	return maxHi
+}
+
+func TestIntervalTree(t *testing.T) {
+	it := &IntervalTree{}
+	var all []Interval
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		lo := r.Intn(1000)
+		iv := Interval{fmt.Sprintf("%04d", lo), fmt.Sprintf("%04d", lo+r.Intn(50)), fmt.Sprint(i)}
+		if err := it.Insert(iv.Lo, iv.Hi, iv.Data); err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, iv)
+	}
+	checkIntervals(t, it.tree.Root, nil, nil)
+	// The AVL invariants are those of `Tree`.
+	if err := it.tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := it.Insert("0002", "0001", ""); err == nil {
+		t.Errorf("inserting an invalid interval succeeded")
+	}
+	if err := it.Insert("a\x00", "b", ""); err == nil {
+		t.Errorf("inserting an end point with the separator succeeded")
+	}
+
+	// Compare with a linear scan. Duplicate intervals keep the data of the last insert.
+	latest := map[[2]string]string{}
+	for _, iv := range all {
+		latest[[2]string{iv.Lo, iv.Hi}] = iv.Data
+	}
+	if it.Len() != len(latest) {
+		t.Fatalf("Len() = %d, want %d", it.Len(), len(latest))
+	}
+	for i := 0; i < 200; i++ {
+		lo := r.Intn(1100)
+		hi := lo + r.Intn(30)
+		if i%2 == 0 {
+			hi = lo
+		}
+		los, his := fmt.Sprintf("%04d", lo), fmt.Sprintf("%04d", hi)
+		want := 0
+		for k := range latest {
+			if k[0] <= his && k[1] >= los {
+				want++
+			}
+		}
+		got := it.Overlapping(los, his)
+		if lo == hi {
+			got = it.Stabbing(los)
+		}
+		if len(got) != want {
+			t.Fatalf("Overlapping(%s, %s) returns %d intervals, want %d", los, his, len(got), want)
+		}
+		for j, iv := range got {
+			if iv.Lo > his || iv.Hi < los || latest[[2]string{iv.Lo, iv.Hi}] != iv.Data {
+				t.Fatalf("Overlapping(%s, %s) returns %v", los, his, iv)
+			}
+			if j > 0 && !got[j-1].less(iv) {
+				t.Fatalf("Overlapping(%s, %s) is not ordered by start", los, his)
+			}
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+// `LinkedTree` is an AVL tree whose nodes also link to their parent.
+//
+// With `Tree`, finding the successor of a node requires a new descent from the root.
+// A `LinkedNode` can step to its successor or predecessor directly, which takes
+// O(1) steps on average when walking through the whole tree. Nodes also serve as stable handles:
+// a `*LinkedNode` returned by `Insert` or `FindNode` stays valid until the node is deleted,
+// and `Delete` removes it without searching the tree again.
+//
+// The zero value is an empty tree that is ready to use.
+type LinkedTree struct {
+	Root *LinkedNode
+	size int
+}
+
+// `LinkedNode` is like `Node`, plus a parent link and a link to the tree it belongs to.
+type LinkedNode struct {
+	Value  string
+	Data   string
+	Left   *LinkedNode
+	Right  *LinkedNode
+	Parent *LinkedNode
+	height int
+	tree   *LinkedTree
+}
+
+// `Height` and `Bal` work like their `Node` counterparts.
+func (n *LinkedNode) Height() int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *LinkedNode) Bal() int {
+	return n.Right.Height() - n.Left.Height()
+}
+
+func (n *LinkedNode) fixHeight() {
+	n.height = max(n.Left.Height(), n.Right.Height()) + 1
+}
+
+// `first` returns the leftmost node of the subtree at `n`, `last` returns the rightmost one.
+func (n *LinkedNode) first() *LinkedNode {
+	for n != nil && n.Left != nil {
+		n = n.Left
+	}
+	return n
+}
+
+func (n *LinkedNode) last() *LinkedNode {
+	for n != nil && n.Right != nil {
+		n = n.Right
+	}
+	return n
+}
+
+// `Next` returns the node with the next larger value, or nil if `n` is the last node.
+func (n *LinkedNode) Next() *LinkedNode {
+	if n == nil {
+		return nil
+	}
+	if n.Right != nil {
+		return n.Right.first()
+	}
+	// Climb up until we arrive from a left child.
+	for n.Parent != nil && n.Parent.Right == n {
+		n = n.Parent
+	}
+	return n.Parent
+}
+
+// `Prev` is the mirrored version of `Next`.
+func (n *LinkedNode) Prev() *LinkedNode {
+	if n == nil {
+		return nil
+	}
+	if n.Left != nil {
+		return n.Left.last()
+	}
+	for n.Parent != nil && n.Parent.Left == n {
+		n = n.Parent
+	}
+	return n.Parent
+}
+
+// `Len` returns the number of nodes.
+func (t *LinkedTree) Len() int {
+	return t.size
+}
+
+// `First` returns the node with the smallest value, or nil if the tree is empty.
+func (t *LinkedTree) First() *LinkedNode {
+	return t.Root.first()
+}
+
+// `Last` returns the node with the largest value, or nil if the tree is empty.
+func (t *LinkedTree) Last() *LinkedNode {
+	return t.Root.last()
+}
+
+// `replaceChild` makes `newChild` take the place of `old` below `parent`, or at the root.
+func (t *LinkedTree) replaceChild(parent, old, newChild *LinkedNode) {
+	switch {
+	case parent == nil:
+		t.Root = newChild
+	case parent.Left == old:
+		parent.Left = newChild
+	default:
+		parent.Right = newChild
+	}
+	if newChild != nil {
+		newChild.Parent = parent
+	}
+}
+
+// `rotateLeft` works like `Node.rotateLeft`, but it also updates the parent links of all
+// nodes that move, and it links the new top node into `n`'s former parent.
+func (t *LinkedTree) rotateLeft(n *LinkedNode) *LinkedNode {
+	r := n.Right
+	n.Right = r.Left
+	if r.Left != nil {
+		r.Left.Parent = n
+	}
+	t.replaceChild(n.Parent, n, r)
+	r.Left = n
+	n.Parent = r
+	n.fixHeight()
+	r.fixHeight()
+	return r
+}
+
+// `rotateRight` is the mirrored version of `rotateLeft`.
+func (t *LinkedTree) rotateRight(n *LinkedNode) *LinkedNode {
+	l := n.Left
+	n.Left = l.Right
+	if l.Right != nil {
+		l.Right.Parent = n
+	}
+	t.replaceChild(n.Parent, n, l)
+	l.Right = n
+	n.Parent = l
+	n.fixHeight()
+	l.fixHeight()
+	return l
+}
+
+// `rebalance` brings the subtree at `n` back into balance and returns its new top node.
+// Unlike `Node.rebalance`, it also handles a taller child with balance 0, which can occur after a delete.
+func (t *LinkedTree) rebalance(n *LinkedNode) *LinkedNode {
+	n.fixHeight()
+	switch {
+	case n.Bal() < -1:
+		if n.Left.Bal() > 0 {
+			t.rotateLeft(n.Left)
+		}
+		return t.rotateRight(n)
+	case n.Bal() > 1:
+		if n.Right.Bal() < 0 {
+			t.rotateRight(n.Right)
+		}
+		return t.rotateLeft(n)
+	}
+	return n
+}
+
+// `Insert` inserts a new node or updates the data of an existing one, and returns that node.
+func (t *LinkedTree) Insert(value, data string) *LinkedNode {
+	var parent *LinkedNode
+	n := t.Root
+	for n != nil {
+		if value == n.Value {
+			n.Data = data
+			return n
+		}
+		parent = n
+		if value < n.Value {
+			n = n.Left
+		} else {
+			n = n.Right
+		}
+	}
+	n = &LinkedNode{Value: value, Data: data, height: 1, tree: t}
+	switch {
+	case parent == nil:
+		t.Root = n
+	case value < parent.Value:
+		parent.Left = n
+	default:
+		parent.Right = n
+	}
+	n.Parent = parent
+	t.size++
+
+	// Walk up until a subtree does not change its height anymore.
+	for p := parent; p != nil; {
+		h := p.height
+		p = t.rebalance(p)
+		if p.height == h {
+			break
+		}
+		p = p.Parent
+	}
+	return n
+}
+
+// `FindNode` returns the node that contains `value`, or nil.
+func (t *LinkedTree) FindNode(value string) *LinkedNode {
+	n := t.Root
+	for n != nil && n.Value != value {
+		if value < n.Value {
+			n = n.Left
+		} else {
+			n = n.Right
+		}
+	}
+	return n
+}
+
+// `Find` returns the data stored for `value`, like `Tree.Find`.
+func (t *LinkedTree) Find(value string) (string, bool) {
+	if n := t.FindNode(value); n != nil {
+		return n.Data, true
+	}
+	return "", false
+}
+
+// `Delete` removes node `n` from the tree. It returns false if `n` is not part of `t`,
+// for example because it has been deleted already.
+//
+// All other nodes keep their identity; `Delete` moves nodes around rather than copying
+// values between them, so that handles to other nodes remain valid.
+func (t *LinkedTree) Delete(n *LinkedNode) bool {
+	if n == nil || n.tree != t {
+		return false
+	}
+	// `fix` is the lowest node whose subtree has changed.
+	var fix *LinkedNode
+	switch {
+	case n.Left == nil:
+		fix = n.Parent
+		t.replaceChild(n.Parent, n, n.Right)
+	case n.Right == nil:
+		fix = n.Parent
+		t.replaceChild(n.Parent, n, n.Left)
+	default:
+		// Two children: the successor `s` takes `n`'s place.
+		s := n.Right.first()
+		if s.Parent == n {
+			fix = s
+		} else {
+			fix = s.Parent
+			t.replaceChild(s.Parent, s, s.Right)
+			s.Right = n.Right
+			s.Right.Parent = s
+		}
+		t.replaceChild(n.Parent, n, s)
+		s.Left = n.Left
+		s.Left.Parent = s
+		s.height = n.height
+	}
+	// A delete can require rotations at several levels, so walk all the way up.
+	for fix != nil {
+		fix = t.rebalance(fix).Parent
+	}
+	n.Left, n.Right, n.Parent, n.tree = nil, nil, nil, nil
+	t.size--
+	return true
+}
+
+// `DeleteValue` finds and deletes the node with the given value.
+func (t *LinkedTree) DeleteValue(value string) bool {
+	return t.Delete(t.FindNode(value))
+}
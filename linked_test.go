@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+// checkLinks verifies parent links, heights, balance, and order of the subtree at n,
+// and returns its height.
+func (n *LinkedNode) checkLinks(tb testing.TB, parent *LinkedNode, lo, hi *string) int {
+	if n == nil {
+		return 0
+	}
+	if n.Parent != parent {
+		tb.Fatalf("node %s has a wrong parent link", n.Value)
+	}
+	if (lo != nil && n.Value <= *lo) || (hi != nil && n.Value >= *hi) {
+		tb.Fatalf("node %s is out of order", n.Value)
+	}
+	lh, rh := n.Left.checkLinks(tb, n, lo, &n.Value), n.Right.checkLinks(tb, n, &n.Value, hi)
+	if h := max(lh, rh) + 1; n.height != h {
+		tb.Fatalf("node %s has height %d, want %d", n.Value, n.height, h)
+	}
+	if rh-lh < -1 || rh-lh > 1 {
+		tb.Fatalf("node %s is unbalanced", n.Value)
+	}
+	return n.height
+}
+
+func TestLinkedTree(t *testing.T) {
+	for _, tree := range trees {
+		t.Run(tree.name, func(t *testing.T) {
+			lt := &LinkedTree{}
+			for i := range tree.value {
+				lt.Insert(tree.value[i], tree.data[i])
+			}
+			lt.Root.checkLinks(t, nil, nil, nil)
+
+			var want []string
+			newTree(tree).Traverse(newTree(tree).Root, func(n *Node) { want = append(want, n.Value) })
+			i := 0
+			for n := lt.First(); n != nil; n = n.Next() {
+				if i >= len(want) || n.Value != want[i] {
+					t.Fatalf("Next: wrong node %s at position %d", n.Value, i)
+				}
+				i++
+			}
+			for n := lt.Last(); n != nil; n = n.Prev() {
+				i--
+				if i < 0 || n.Value != want[i] {
+					t.Fatalf("Prev: wrong node %s at position %d", n.Value, i)
+				}
+			}
+			if i != 0 || lt.Len() != len(want) {
+				t.Errorf("tree has %d nodes, want %d", lt.Len(), len(want))
+			}
+		})
+	}
+}
+
+func TestLinkedTree_Delete(t *testing.T) {
+	lt := &LinkedTree{}
+	handles := map[string]*LinkedNode{}
+	insert := modelOp{
+		name: "insert",
+		do: func(k, d string) string {
+			n := lt.Insert(k, d)
+			if h, exists := handles[k]; exists && h != n {
+				return "a new handle"
+			}
+			handles[k] = n
+			return ""
+		},
+		model: modelInsert,
+	}
+	model := runModelTest(t, modelTest{
+		ops: []modelOp{insert, insert, {
+			name: "delete",
+			do: func(k, _ string) string {
+				h := handles[k]
+				deleted := lt.Delete(h)
+				if lt.Delete(h) {
+					return "deleted twice"
+				}
+				delete(handles, k)
+				return strconv.FormatBool(deleted)
+			},
+			model: modelDelete,
+		}},
+		keys:  200,
+		steps: 5000,
+		check: func(tb testing.TB) { lt.Root.checkLinks(tb, nil, nil, nil) },
+		walk: func(f func(k, d string)) {
+			for n := lt.First(); n != nil; n = n.Next() {
+				f(n.Value, n.Data)
+			}
+		},
+	})
+
+	for k, h := range handles {
+		// Every handle must still point to its own value.
+		if h.Value != k || lt.FindNode(k) != h {
+			t.Fatalf("handle for %s points to %s", k, h.Value)
+		}
+	}
+	if len(handles) != len(model) || lt.Len() != len(model) {
+		t.Errorf("tree has %d handles (Len: %d), want %d", len(handles), lt.Len(), len(model))
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// modelOp is one kind of operation in a modelTest. do applies it to the data structure
+// under test, model applies it to a map that mirrors the data structure. Both return
+// the result of the operation, and the results must be equal.
+type modelOp struct {
+	name  string
+	do    func(k, d string) string
+	model func(m map[string]string, k, d string) string
+}
+
+// modelTest describes a data structure for runModelTest.
+type modelTest struct {
+	// ops are picked at random. An op that is listed twice is picked twice as often.
+	ops []modelOp
+	// keys are drawn from "0" to keys-1, data from "0" to "4".
+	keys, steps int
+	// check verifies the invariants of the data structure.
+	check func(tb testing.TB)
+	// walk calls f for each key and its data in sort order, in the same form as the model.
+	walk func(f func(k, d string))
+}
+
+// runModelTest runs random operations on a data structure and on a map model side by side.
+// After each step, it checks the invariants and compares the contents. It returns the model,
+// so that tests can check further methods against it.
+func runModelTest(t *testing.T, mt modelTest) map[string]string {
+	t.Helper()
+	model := map[string]string{}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < mt.steps; i++ {
+		k, d := strconv.Itoa(r.Intn(mt.keys)), strconv.Itoa(r.Intn(5))
+		op := mt.ops[r.Intn(len(mt.ops))]
+		want := op.model(model, k, d)
+		if got := op.do(k, d); got != want {
+			t.Fatalf("step %d: %s(%s, %s) = %s, want %s", i, op.name, k, d, got, want)
+		}
+		mt.check(t)
+		keys := sortedKeys(model)
+		j := 0
+		mt.walk(func(k, d string) {
+			if j >= len(keys) || k != keys[j] || d != model[k] {
+				t.Fatalf("step %d: walk yields %s: %s at position %d; want keys %v", i, k, d, j, keys)
+			}
+			j++
+		})
+		if j != len(keys) {
+			t.Fatalf("step %d: walk yields %d keys, want %d", i, j, len(keys))
+		}
+	}
+	return model
+}
+
+// mapOps are the operations of a map-like data structure: insert, which replaces existing data,
+// and delete, which tells whether the key existed. Inserts are twice as frequent.
+func mapOps(insert func(k, d string), del func(k string) bool) []modelOp {
+	ins := modelOp{
+		name:  "insert",
+		do:    func(k, d string) string { insert(k, d); return "" },
+		model: modelInsert,
+	}
+	return []modelOp{ins, ins, {
+		name:  "delete",
+		do:    func(k, _ string) string { return strconv.FormatBool(del(k)) },
+		model: modelDelete,
+	}}
+}
+
+func modelInsert(m map[string]string, k, d string) string {
+	m[k] = d
+	return ""
+}
+
+func modelDelete(m map[string]string, k, _ string) string {
+	_, exists := m[k]
+	delete(m, k)
+	return strconv.FormatBool(exists)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// `MultiMap` is a `Tree` that allows duplicate values.
+//
+// Where `Tree.Insert` replaces the data of an existing value ("Upsert semantics"), `MultiMap.Insert`
+// adds another entry. Each entry is a node of its own, whose value is the entry's value, followed by
+// a separator and a sequence number. All entries of a value thus sort next to each other,
+// in insertion order, and the tree does the balancing as usual.
+//
+// Values must not contain the byte 0, which serves as the separator.
+//
+// The zero value is an empty multimap that is ready to use.
+type MultiMap struct {
+	tree Tree
+	size int
+	// `seq` numbers the entries in insertion order.
+	seq uint64
+}
+
+const multiSep = "\x00"
+
+// `entryValue` makes the value of the node for an entry. The sequence number has a fixed width
+// so that the numbers sort like their strings.
+func entryValue(value string, seq uint64) string {
+	return fmt.Sprintf("%s%s%016x", value, multiSep, seq)
+}
+
+// `entries` calls `f` for each entry of `value`, in insertion order, until `f` returns false.
+// As the separator is the smallest byte, the entries of `value` are exactly the values between
+// value + "\x00" and value + "\x01".
+func (m *MultiMap) entries(value string, f func(*Node) bool) {
+	m.tree.Range(value+multiSep, value+"\x01", f)
+}
+
+// `Insert` adds `data` to the entries of `value`. Existing entries are kept.
+func (m *MultiMap) Insert(value, data string) error {
+	if strings.Contains(value, multiSep) {
+		return fmt.Errorf("invalid value %q: values must not contain the byte 0", value)
+	}
+	m.seq++
+	m.tree.Insert(entryValue(value, m.seq), data)
+	m.size++
+	return nil
+}
+
+// `FindAll` returns all data for `value` in insertion order, or nil if there is no such value.
+func (m *MultiMap) FindAll(value string) []string {
+	var data []string
+	m.entries(value, func(n *Node) bool {
+		data = append(data, n.Data)
+		return true
+	})
+	return data
+}
+
+// `Count` returns the number of entries for `value`.
+func (m *MultiMap) Count(value string) int {
+	c := 0
+	m.entries(value, func(*Node) bool {
+		c++
+		return true
+	})
+	return c
+}
+
+// `Len` returns the number of entries in the multimap, counting each duplicate.
+func (m *MultiMap) Len() int {
+	return m.size
+}
+
+// `DeleteAll` removes all entries for `value` and returns how many there were.
+func (m *MultiMap) DeleteAll(value string) int {
+	// Collect first; deleting during `Range` would change the tree under its feet.
+	var keys []string
+	m.entries(value, func(n *Node) bool {
+		keys = append(keys, n.Value)
+		return true
+	})
+	for _, k := range keys {
+		m.tree.Delete(k)
+	}
+	m.size -= len(keys)
+	return len(keys)
+}
+
+// `DeleteOne` removes the oldest entry of `value` whose data equals `data`.
+// `DeleteOne` returns false if there was no such entry.
+func (m *MultiMap) DeleteOne(value, data string) bool {
+	key, found := "", false
+	m.entries(value, func(n *Node) bool {
+		if n.Data == data {
+			key, found = n.Value, true
+		}
+		return !found
+	})
+	if found {
+		m.tree.Delete(key)
+		m.size--
+	}
+	return found
+}
+
+// `Walk` calls `f` for each entry in sort order. Entries with the same value are
+// passed in insertion order.
+func (m *MultiMap) Walk(f func(value, data string)) {
+	m.tree.Traverse(m.tree.Root, func(n *Node) {
+		f(n.Value[:strings.LastIndex(n.Value, multiSep)], n.Data)
+	})
+}
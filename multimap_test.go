@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMultiMap(t *testing.T) {
+	mm := &MultiMap{}
+	// The data are single digits, so the model simply concatenates the data of each value.
+	insert := modelOp{
+		name: "Insert",
+		do: func(k, d string) string {
+			if err := mm.Insert(k, d); err != nil {
+				return err.Error()
+			}
+			return ""
+		},
+		model: func(m map[string]string, k, d string) string {
+			m[k] += d
+			return ""
+		},
+	}
+	deleteOne := modelOp{
+		name: "DeleteOne",
+		do:   func(k, d string) string { return strconv.FormatBool(mm.DeleteOne(k, d)) },
+		model: func(m map[string]string, k, d string) string {
+			i := strings.Index(m[k], d)
+			if i < 0 {
+				return "false"
+			}
+			if m[k] = m[k][:i] + m[k][i+1:]; m[k] == "" {
+				delete(m, k)
+			}
+			return "true"
+		},
+	}
+	deleteAll := modelOp{
+		name: "DeleteAll",
+		do:   func(k, _ string) string { return strconv.Itoa(mm.DeleteAll(k)) },
+		model: func(m map[string]string, k, _ string) string {
+			n := len(m[k])
+			delete(m, k)
+			return strconv.Itoa(n)
+		},
+	}
+	model := runModelTest(t, modelTest{
+		ops:   []modelOp{insert, insert, insert, insert, deleteOne, deleteOne, deleteAll},
+		keys:  100,
+		steps: 5000,
+		check: func(tb testing.TB) {
+			if err := mm.tree.Validate(); err != nil {
+				tb.Fatal(err)
+			}
+		},
+		// Walk yields each duplicate. The entries of a value come in a row, in insertion order.
+		walk: func(f func(k, d string)) {
+			key, ds := "", ""
+			mm.Walk(func(v, d string) {
+				if v != key && ds != "" {
+					f(key, ds)
+					ds = ""
+				}
+				key, ds = v, ds+d
+			})
+			if ds != "" {
+				f(key, ds)
+			}
+		},
+	})
+
+	size := 0
+	for k, ds := range model {
+		if got := strings.Join(mm.FindAll(k), ""); got != ds || mm.Count(k) != len(ds) {
+			t.Fatalf("FindAll(%s) = %v, Count(%s) = %d; want %s", k, mm.FindAll(k), k, mm.Count(k), ds)
+		}
+		size += len(ds)
+	}
+	if mm.Len() != size {
+		t.Fatalf("Len() = %d, want %d", mm.Len(), size)
+	}
+	if mm.FindAll("none") != nil || mm.Count("none") != 0 || mm.DeleteAll("none") != 0 {
+		t.Errorf("missing value is reported as present")
+	}
+	if err := mm.Insert("a\x00", ""); err == nil {
+		t.Errorf("Insert accepts a value with the byte 0")
+	}
+}
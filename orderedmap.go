@@ -0,0 +1,32 @@
+package main
+
+// `OrderedMap` is the public API of `Tree`, abstracted from the balancing strategy.
+//
+// `Tree.Traverse` expects a `*Node` to start from, which ties it to `Tree`'s node type.
+// The interface therefore uses `Walk`, which always starts at the root and passes
+// values and data instead of nodes.
+type OrderedMap interface {
+	// `Insert` inserts a value, or updates the data if the value exists already.
+	Insert(value, data string)
+	// `Find` returns the data for a value and true, or "" and false if there is no such value.
+	Find(value string) (string, bool)
+	// `Walk` calls `f` for each value and its data in sort order.
+	Walk(f func(value, data string))
+	// `Dump` prints the structure of the tree.
+	Dump()
+}
+
+var (
+	_ OrderedMap = &Tree{}
+	_ OrderedMap = &RBTree{}
+	_ OrderedMap = &Treap{}
+	_ OrderedMap = &SplayTree{}
+	_ OrderedMap = &BTree{}
+	_ OrderedMap = &WBTree{}
+	_ OrderedMap = &ScapegoatTree{}
+)
+
+// `Walk` traverses the whole tree in sort order.
+func (t *Tree) Walk(f func(value, data string)) {
+	t.Traverse(t.Root, func(n *Node) { f(n.Value, n.Data) })
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"testing"
+)
+
+// orderedMaps lists all implementations of OrderedMap. check verifies the
+// implementation-specific invariants, and calls tb.Fatal if they are violated.
+var orderedMaps = []struct {
+	name  string
+	new   func() OrderedMap
+	check func(tb testing.TB, m OrderedMap)
+}{
+	{"Tree", func() OrderedMap { return &Tree{} }, func(tb testing.TB, m OrderedMap) {
+		if err := m.(*Tree).Validate(); err != nil {
+			tb.Fatal(err)
+		}
+	}},
+	{"RBTree", func() OrderedMap { return &RBTree{} }, func(tb testing.TB, m OrderedMap) {
+		if m.(*RBTree).Root.isRed() {
+			tb.Fatal("root is red")
+		}
+		m.(*RBTree).Root.checkColors(tb, nil, nil)
+	}},
+	{"Treap", func() OrderedMap { return NewTreap(42) }, func(tb testing.TB, m OrderedMap) {
+		m.(*Treap).Root.checkHeap(tb, nil, nil)
+	}},
+	{"SplayTree", func() OrderedMap { return &SplayTree{} }, func(tb testing.TB, m OrderedMap) {
+		if !m.(*SplayTree).Root.isSorted(nil, nil) {
+			tb.Fatal("tree is not sorted")
+		}
+	}},
+	{"BTree", func() OrderedMap { return NewBTree(3) }, func(tb testing.TB, m OrderedMap) {
+		if bt := m.(*BTree); bt.root != nil {
+			bt.root.checkBTree(tb, bt.degree, true, nil, nil)
+		}
+	}},
+	{"WBTree", func() OrderedMap { return &WBTree{} }, func(tb testing.TB, m OrderedMap) {
+		m.(*WBTree).Root.checkWeights(tb, nil, nil)
+	}},
+	{"ScapegoatTree", func() OrderedMap { return NewScapegoatTree(0.6) }, func(tb testing.TB, m OrderedMap) {
+		m.(*ScapegoatTree).checkDepth(tb)
+	}},
+}
+
+// TestOrderedMap runs the fixtures from balancedtree_test.go against each implementation
+// and checks that the result is the same as with Tree.
+func TestOrderedMap(t *testing.T) {
+	for _, om := range orderedMaps {
+		for _, tree := range trees {
+			t.Run(om.name+"/"+tree.name, func(t *testing.T) {
+				m := om.new()
+				for i := range tree.value {
+					m.Insert(tree.value[i], tree.data[i])
+					om.check(t, m)
+				}
+				want := newTree(tree)
+				if v, ok := containsAll(m, tree); !ok {
+					t.Errorf("value %s is missing", v)
+				}
+				var got []string
+				m.Walk(func(v, d string) { got = append(got, v+":"+d) })
+				i := 0
+				want.Walk(func(v, d string) {
+					if i >= len(got) || got[i] != v+":"+d {
+						t.Fatalf("Walk yields %v, differs from Tree at position %d", got, i)
+					}
+					i++
+				})
+				if i != len(got) {
+					t.Errorf("Walk yields %d values, want %d", len(got), i)
+				}
+			})
+		}
+	}
+}
+
+func containsAll(m OrderedMap, source tree) (string, bool) {
+	for _, v := range source.value {
+		if _, found := m.Find(v); !found {
+			return v, false
+		}
+	}
+	return "", true
+}
+
+// checkColors verifies the red-black invariants of the subtree at n and returns its black height.
+func (n *RBNode) checkColors(tb testing.TB, lo, hi *string) int {
+	if n == nil {
+		return 1
+	}
+	if (lo != nil && n.Value <= *lo) || (hi != nil && n.Value >= *hi) {
+		tb.Fatalf("node %s is out of order", n.Value)
+	}
+	if n.red && (n.Left.isRed() || n.Right.isRed()) {
+		tb.Fatalf("red node %s has a red child", n.Value)
+	}
+	if n.Right.isRed() {
+		tb.Fatalf("node %s has a red right child", n.Value)
+	}
+	lb, rb := n.Left.checkColors(tb, lo, &n.Value), n.Right.checkColors(tb, &n.Value, hi)
+	if lb != rb {
+		tb.Fatalf("node %s has black heights %d and %d", n.Value, lb, rb)
+	}
+	if n.red {
+		return lb
+	}
+	return lb + 1
+}
+
+func TestRBTree_colors(t *testing.T) {
+	for _, tree := range trees {
+		t.Run(tree.name, func(t *testing.T) {
+			rb := &RBTree{}
+			for i := range tree.value {
+				rb.Insert(tree.value[i], tree.data[i])
+				if rb.Root.isRed() {
+					t.Fatalf("root is red")
+				}
+				rb.Root.checkColors(t, nil, nil)
+			}
+		})
+	}
+}
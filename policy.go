@@ -0,0 +1,84 @@
+package main
+
+import "fmt"
+
+// `Policy` decides how strictly a `Tree` keeps itself in balance.
+//
+// The zero value is the AVL policy that the article describes: the heights of the
+// two child subtrees of any node differ by at most one.
+type Policy struct {
+	name string
+	// `slack` is the additional height difference that is tolerated on top of AVL's one.
+	slack int
+	// `off` disables rebalancing altogether.
+	off bool
+}
+
+var (
+	// `AVL` is the default policy.
+	AVL = Policy{}
+	// `NoBalancing` turns `Tree` back into the plain binary search tree from the previous article.
+	NoBalancing = Policy{name: "none", off: true}
+)
+
+// `RelaxedAVL` tolerates height differences of up to `k` between the subtrees of a node.
+// Higher values of `k` mean fewer rotations but higher trees. `RelaxedAVL(1)` is the same as `AVL`.
+func RelaxedAVL(k int) Policy {
+	if k < 1 {
+		k = 1
+	}
+	return Policy{name: fmt.Sprintf("relaxed AVL (k=%d)", k), slack: k - 1}
+}
+
+func (p Policy) String() string {
+	if p.name == "" {
+		return "AVL"
+	}
+	return p.name
+}
+
+// `maxBal` returns the largest tolerated height difference, or -1 if the policy does no balancing.
+func (p Policy) maxBal() int {
+	if p.off {
+		return -1
+	}
+	return 1 + p.slack
+}
+
+// `NewTree` creates an empty tree that balances itself according to policy `p`.
+// A zero `Tree` uses the `AVL` policy.
+func NewTree(p Policy) *Tree {
+	return &Tree{policy: p}
+}
+
+// `Policy` returns the tree's balancing policy.
+func (t *Tree) Policy() Policy {
+	if t == nil {
+		return AVL
+	}
+	return t.policy
+}
+
+// `Stats` describes the work a tree has done for staying in balance, and the result.
+type Stats struct {
+	Policy Policy
+	// `Rotations` counts single rotations. A double rotation counts as two.
+	Rotations int
+	Height    int
+}
+
+// `Stats` returns the rotation count and the current height of the tree.
+func (t *Tree) Stats() Stats {
+	return Stats{
+		Policy:    t.policy,
+		Rotations: t.rotations,
+		Height:    t.Root.Height(),
+	}
+}
+
+// `countRotation` works with a nil receiver, like `recorder`.
+func (t *Tree) countRotation() {
+	if t != nil {
+		t.rotations++
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+var policies = []Policy{AVL, RelaxedAVL(2), RelaxedAVL(3), NoBalancing}
+
+func TestPolicy(t *testing.T) {
+	for _, p := range policies {
+		for _, tree := range trees {
+			t.Run(p.String()+"/"+tree.name, func(t *testing.T) {
+				tt := NewTree(p)
+				for i := range tree.value {
+					tt.Insert(tree.value[i], tree.data[i])
+				}
+				if err := tt.Validate(); err != nil {
+					t.Error(err)
+				}
+				if v, ok := tt.containsAllElements(tree); !ok {
+					t.Errorf("value %s is missing", v)
+				}
+			})
+		}
+	}
+}
+
+func TestPolicy_Stats(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	values := make([]string, 2000)
+	for i := range values {
+		values[i] = strconv.Itoa(r.Int())
+	}
+	var stats []Stats
+	for _, p := range policies {
+		tt := NewTree(p)
+		for _, v := range values {
+			tt.Insert(v, v)
+		}
+		if err := tt.Validate(); err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+		stats = append(stats, tt.Stats())
+		t.Logf("%s: %d rotations, height %d", p, tt.Stats().Rotations, tt.Stats().Height)
+	}
+	// Each relaxation must not need more rotations than the stricter policy before it.
+	for i := 1; i < len(stats); i++ {
+		if stats[i].Rotations > stats[i-1].Rotations {
+			t.Errorf("%s needs more rotations than %s", stats[i].Policy, stats[i-1].Policy)
+		}
+	}
+	if stats[len(stats)-1].Rotations != 0 {
+		t.Errorf("NoBalancing has rotated")
+	}
+
+	// Sorted input turns an unbalanced tree into a list.
+	tt := NewTree(NoBalancing)
+	for i := 0; i < 100; i++ {
+		tt.Insert(strconv.Itoa(1000+i), "")
+	}
+	if h := tt.Stats().Height; h != 100 {
+		t.Errorf("NoBalancing with sorted input: height %d, want 100", h)
+	}
+}
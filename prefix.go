@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Values are compared byte by byte, so all values with a common prefix form a contiguous
+// range of the tree. `PrefixScan` and `LongestPrefixOf` build on that.
+//
+// Both functions work on bytes but match only at rune boundaries: a prefix that ends in the
+// middle of a UTF-8 encoded rune does not count. For example, "caf\xc3" (the first byte of "é" is
+// missing its second byte) is not a prefix of "café" in this sense. For valid UTF-8 input,
+// this makes no difference; for truncated input, it avoids matches that split a character.
+
+// `PrefixScan` returns the nodes whose values start with `prefix`, in sort order.
+// It returns at most `limit` nodes; if `limit` is 0 or less, it returns all of them.
+func (t *Tree) PrefixScan(prefix string, limit int) []*Node {
+	var nodes []*Node
+	t.Range(prefix, prefixEnd(prefix), func(n *Node) bool {
+		if !onRuneBoundary(n.Value, len(prefix)) {
+			return true
+		}
+		nodes = append(nodes, n)
+		return limit <= 0 || len(nodes) < limit
+	})
+	return nodes
+}
+
+// `prefixEnd` returns the smallest string that is larger than all strings starting with `prefix`,
+// or "" if there is no such string, as for "" or "\xff\xff". This is the exclusive upper bound of
+// the prefix range, in the form that `Range` expects.
+func prefixEnd(prefix string) string {
+	b := []byte(prefix)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 0xff {
+			b[i]++
+			return string(b[:i+1])
+		}
+	}
+	return ""
+}
+
+// `onRuneBoundary` tells whether `s[:i]` ends at a rune boundary of `s`.
+func onRuneBoundary(s string, i int) bool {
+	return i == len(s) || utf8.RuneStart(s[i])
+}
+
+// `LongestPrefixOf` returns the longest value in the tree that is a prefix of `s`, and its data,
+// as needed for a routing table. `found` is false if no value is a prefix of `s`.
+//
+// Any value that is a prefix of `s` sorts before `s`, and before all values between itself and `s`.
+// So the candidate is the largest value not greater than `s`. If that is no prefix of `s`,
+// the answer must be a prefix of the part that both have in common, and the search repeats with
+// that part. The search string gets shorter on every round, so `LongestPrefixOf` takes at most
+// O(len(s) log n) steps, but typically only a few rounds.
+func (t *Tree) LongestPrefixOf(s string) (value, data string, found bool) {
+	q := s
+	for {
+		f := t.Root.floor(q)
+		if f == nil {
+			return "", "", false
+		}
+		switch {
+		case !strings.HasPrefix(q, f.Value):
+			q = q[:commonPrefixLen(q, f.Value)]
+		case onRuneBoundary(s, len(f.Value)):
+			return f.Value, f.Data, true
+		default:
+			// `f` splits a rune of `s`. Shorter values may still match.
+			q = f.Value[:len(f.Value)-1]
+		}
+	}
+}
+
+// `floor` returns the node with the largest value not greater than `s`, or nil.
+func (n *Node) floor(s string) *Node {
+	var f *Node
+	for n != nil {
+		switch {
+		case s == n.Value:
+			return n
+		case s < n.Value:
+			n = n.Left
+		default:
+			f = n
+			n = n.Right
+		}
+	}
+	return f
+}
+
+func commonPrefixLen(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
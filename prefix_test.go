@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func values(nodes []*Node) []string {
+	vs := []string{}
+	for _, n := range nodes {
+		vs = append(vs, n.Value)
+	}
+	return vs
+}
+
+func TestTree_PrefixScan(t *testing.T) {
+	tree := &Tree{}
+	for _, v := range []string{"ca", "car", "card", "care", "cat", "cb", "c", "caf", "café", "caf\xc3", "caf\xc3x", "\xff", "\xff\xff", "\xff\xffa"} {
+		tree.Insert(v, "")
+	}
+	tests := []struct {
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{"car", 0, []string{"car", "card", "care"}},
+		{"car", 2, []string{"car", "card"}},
+		{"ca", 0, []string{"ca", "caf", "caf\xc3", "caf\xc3x", "café", "car", "card", "care", "cat"}},
+		{"caf", 0, []string{"caf", "caf\xc3", "caf\xc3x", "café"}},
+		// The truncated "é" matches only where it does not split a rune.
+		{"caf\xc3", 0, []string{"caf\xc3", "caf\xc3x"}},
+		{"café", 0, []string{"café"}},
+		{"d", 0, []string{}},
+		{"\xff", 0, []string{"\xff", "\xff\xff", "\xff\xffa"}},
+		{"\xff\xff", 0, []string{"\xff\xff", "\xff\xffa"}},
+		{"", 3, []string{"c", "ca", "caf"}},
+	}
+	for _, tt := range tests {
+		if got := values(tree.PrefixScan(tt.prefix, tt.limit)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PrefixScan(%q, %d) = %q, want %q", tt.prefix, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestTree_LongestPrefixOf(t *testing.T) {
+	tree := &Tree{}
+	for _, v := range []string{"/", "/api", "/api/v1", "/api/v1/users", "/apiary", "/static", "/é", "/\xc3"} {
+		tree.Insert(v, "route "+v)
+	}
+	tests := []struct {
+		s, want string
+	}{
+		{"/api/v1/users/42", "/api/v1/users"},
+		{"/api/v1/user", "/api/v1"},
+		{"/api/v2", "/api"},
+		{"/apia", "/api"},
+		{"/apiary/bees", "/apiary"},
+		{"/zzz", "/"},
+		{"/", "/"},
+		{"/év", "/é"},
+		// "/\xc3" is a byte prefix of "/è", but it would split the rune.
+		{"/è", "/"},
+		{"/\xc3", "/\xc3"},
+	}
+	for _, tt := range tests {
+		v, d, found := tree.LongestPrefixOf(tt.s)
+		if !found || v != tt.want || d != "route "+tt.want {
+			t.Errorf("LongestPrefixOf(%q) = %q, %q, %t, want %q", tt.s, v, d, found, tt.want)
+		}
+	}
+	if v, _, found := tree.LongestPrefixOf("api"); found {
+		t.Errorf("LongestPrefixOf(\"api\") found %q", v)
+	}
+	if _, _, found := (&Tree{}).LongestPrefixOf("x"); found {
+		t.Errorf("empty tree found a prefix")
+	}
+}
+
+// Compare both functions to a linear scan over random keys from a small alphabet, including bytes of multi-byte runes.
+func TestPrefixRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []string{"a", "b", "é", "\xc3", "\xff"}
+	word := func() string {
+		var b strings.Builder
+		for i := rng.Intn(5); i > 0; i-- {
+			b.WriteString(alphabet[rng.Intn(len(alphabet))])
+		}
+		return b.String()
+	}
+	tree := &Tree{}
+	model := map[string]string{}
+	for i := 0; i < 300; i++ {
+		k := word()
+		tree.Insert(k, k)
+		model[k] = k
+	}
+	keys := sortedKeys(model)
+	matches := func(k, p string) bool {
+		return strings.HasPrefix(k, p) && (len(k) == len(p) || utf8.RuneStart(k[len(p)]))
+	}
+	for i := 0; i < 300; i++ {
+		q := word()
+		want := []string{}
+		longest, found := "", false
+		for _, k := range keys {
+			if matches(k, q) {
+				want = append(want, k)
+			}
+			if matches(q, k) && (!found || len(k) > len(longest)) {
+				longest, found = k, true
+			}
+		}
+		if got := values(tree.PrefixScan(q, 0)); !reflect.DeepEqual(got, want) {
+			t.Fatalf("PrefixScan(%q) = %q, want %q", q, got, want)
+		}
+		if v, _, ok := tree.LongestPrefixOf(q); ok != found || v != longest {
+			t.Fatalf("LongestPrefixOf(%q) = %q, %t, want %q, %t", q, v, ok, longest, found)
+		}
+	}
+}
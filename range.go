@@ -0,0 +1,29 @@
+package main
+
+// `Range` calls `f` for each node whose value `v` satisfies `from <= v < to`, in sort order,
+// until `f` returns false. An empty `to` means there is no upper bound.
+//
+// Unlike `Traverse`, `Range` skips all subtrees that lie completely outside the range,
+// so it visits only O(log n + k) nodes for k matching values.
+func (t *Tree) Range(from, to string, f func(*Node) bool) {
+	t.Root.walkRange(from, to, f)
+}
+
+// `walkRange` returns false if `f` asked to stop or if the walk has passed `to`.
+func (n *Node) walkRange(from, to string, f func(*Node) bool) bool {
+	if n == nil {
+		return true
+	}
+	if from < n.Value {
+		if !n.Left.walkRange(from, to, f) {
+			return false
+		}
+	}
+	if to != "" && n.Value >= to {
+		return false
+	}
+	if from <= n.Value && !f(n) {
+		return false
+	}
+	return n.Right.walkRange(from, to, f)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// `RBTree` is a red-black tree, an alternative to the AVL balancing of `Tree`.
+//
+// Instead of a height, each node stores a color. The tree is balanced if the root is black,
+// no red node has a red child, and every path from the root down to a missing child passes
+// through the same number of black nodes. This is a weaker condition than AVL balance,
+// so inserts need fewer rotations, but the tree can become up to twice as high as the ideal height.
+//
+// This implementation is a left-leaning red-black tree (after Robert Sedgewick), where
+// red nodes can only be left children. This reduces the number of cases considerably.
+//
+// The zero value is an empty tree that is ready to use.
+type RBTree struct {
+	Root *RBNode
+}
+
+// `RBNode` is a node of an `RBTree`.
+type RBNode struct {
+	Value string
+	Data  string
+	Left  *RBNode
+	Right *RBNode
+	red   bool
+}
+
+// `isRed` works with nil nodes, which are black.
+func (n *RBNode) isRed() bool {
+	return n != nil && n.red
+}
+
+// `rotateLeft` and `rotateRight` are the same rotations as in `Node`. The new top node
+// takes over the color of the old one, and the old one becomes red.
+func (n *RBNode) rotateLeft() *RBNode {
+	r := n.Right
+	n.Right = r.Left
+	r.Left = n
+	r.red = n.red
+	n.red = true
+	return r
+}
+
+func (n *RBNode) rotateRight() *RBNode {
+	l := n.Left
+	n.Left = l.Right
+	l.Right = n
+	l.red = n.red
+	n.red = true
+	return l
+}
+
+// `flipColors` splits a temporary 4-node: both children turn black, and the node turns red.
+func (n *RBNode) flipColors() {
+	n.red = !n.red
+	n.Left.red = !n.Left.red
+	n.Right.red = !n.Right.red
+}
+
+// `insert` inserts a value into the subtree at `n` and fixes the colors on the way back up.
+func (n *RBNode) insert(value, data string) *RBNode {
+	if n == nil {
+		return &RBNode{Value: value, Data: data, red: true}
+	}
+	switch {
+	case value == n.Value:
+		n.Data = data
+	case value < n.Value:
+		n.Left = n.Left.insert(value, data)
+	default:
+		n.Right = n.Right.insert(value, data)
+	}
+
+	// A red right child leans the wrong way.
+	if n.Right.isRed() && !n.Left.isRed() {
+		n = n.rotateLeft()
+	}
+	// Two red nodes in a row.
+	if n.Left.isRed() && n.Left.Left.isRed() {
+		n = n.rotateRight()
+	}
+	// Both children are red.
+	if n.Left.isRed() && n.Right.isRed() {
+		n.flipColors()
+	}
+	return n
+}
+
+// `Insert` inserts a value or updates the data of an existing one.
+func (t *RBTree) Insert(value, data string) {
+	t.Root = t.Root.insert(value, data)
+	t.Root.red = false
+}
+
+// `Find` returns the data stored for `value`.
+func (t *RBTree) Find(value string) (string, bool) {
+	n := t.Root
+	for n != nil {
+		switch {
+		case value == n.Value:
+			return n.Data, true
+		case value < n.Value:
+			n = n.Left
+		default:
+			n = n.Right
+		}
+	}
+	return "", false
+}
+
+// `Walk` calls `f` for each value and its data in sort order.
+func (t *RBTree) Walk(f func(value, data string)) {
+	var walk func(*RBNode)
+	walk = func(n *RBNode) {
+		if n == nil {
+			return
+		}
+		walk(n.Left)
+		f(n.Value, n.Data)
+		walk(n.Right)
+	}
+	walk(t.Root)
+}
+
+// `Dump` prints the tree like `Tree.Dump`, but with the node colors instead of balance and height.
+func (t *RBTree) Dump() {
+	t.Root.Dump(0, "")
+}
+
+func (n *RBNode) Dump(i int, lr string) {
+	dumpTree(os.Stdout, n, i, lr, func(n *RBNode) (string, *RBNode, *RBNode) {
+		color := "B"
+		if n.red {
+			color = "R"
+		}
+		return fmt.Sprintf("%s[%s]", n.Value, color), n.Left, n.Right
+	})
+}
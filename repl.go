@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// `REPL` lets users explore a tree interactively. After each command that changes the tree,
+// it prints the rotations that fired and the new tree structure.
+//
+//	insert <key> [<data>]   insert or update a key
+//	find <key>              look up a key
+//	delete <key>            delete a key
+//	dump                    print the tree with balance and height, like `Tree.Dump`
+//	pretty                  print the tree turned by 90°, like `Tree.PrettyPrint`
+//	undo                    revert the last insert or delete (up to 20 times)
+//	history                 list the commands entered so far
+//	!!, !<n>                repeat the last or the n-th command
+//	help                    list the commands
+//	quit, exit              leave the REPL
+//
+// The standard library has no line editor, so instead of arrow keys, `history` and `!<n>` give
+// access to earlier commands.
+type REPL struct {
+	tree    *Tree
+	out     io.Writer
+	history []string
+	// `undo` holds one entry per change to the tree: the command and the tree before that command.
+	// As each entry is a copy of the whole tree, only the last `maxUndo` changes can be undone.
+	undo []undoEntry
+}
+
+const maxUndo = 20
+
+type undoEntry struct {
+	cmd  string
+	root *Snapshot
+}
+
+// `errQuit` ends `REPL.Run`.
+var errQuit = errors.New("quit")
+
+// `NewREPL` creates a REPL that works on `t` and writes all output to `out`.
+func NewREPL(t *Tree, out io.Writer) *REPL {
+	if t == nil {
+		t = &Tree{}
+	}
+	return &REPL{tree: t, out: out}
+}
+
+// `Run` reads commands from `in` line by line until the input ends or the user quits.
+// If `interactive` is false, as for a script, each command is echoed after the prompt, and the first
+// failing command stops the script. Otherwise, errors are printed and the REPL continues.
+// Empty lines and lines starting with # are skipped.
+func (r *REPL) Run(in io.Reader, interactive bool) error {
+	s := bufio.NewScanner(in)
+	for line := 1; ; line++ {
+		fmt.Fprint(r.out, "> ")
+		if !s.Scan() {
+			fmt.Fprintln(r.out)
+			return s.Err()
+		}
+		cmd := strings.TrimSpace(s.Text())
+		if !interactive {
+			fmt.Fprintln(r.out, cmd)
+		}
+		if cmd == "" || strings.HasPrefix(cmd, "#") {
+			continue
+		}
+		err := r.Exec(cmd)
+		switch {
+		case err == errQuit:
+			return nil
+		case err != nil && interactive:
+			fmt.Fprintln(r.out, "error:", err)
+		case err != nil:
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+// `Exec` runs a single command.
+func (r *REPL) Exec(cmd string) error {
+	if strings.HasPrefix(cmd, "!") {
+		var err error
+		if cmd, err = r.recall(cmd); err != nil {
+			return err
+		}
+		fmt.Fprintln(r.out, cmd)
+	}
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		// `Run` skips empty lines, but other callers might not.
+		return fmt.Errorf("empty command")
+	}
+	r.history = append(r.history, cmd)
+
+	args := fields[1:]
+	switch name := fields[0]; name {
+	case "insert", "i":
+		if len(args) < 1 {
+			return fmt.Errorf("usage: insert <key> [<data>]")
+		}
+		// The data is the rest of the line and may contain spaces.
+		data := strings.Join(args[1:], " ")
+		r.change(cmd, func() { r.tree.Insert(args[0], data) })
+	case "delete", "d":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: delete <key>")
+		}
+		// Check first, so that a failed delete leaves nothing to undo.
+		if _, found := r.tree.Find(args[0]); !found {
+			return fmt.Errorf("%s not found", args[0])
+		}
+		r.change(cmd, func() { r.tree.Delete(args[0]) })
+	case "find", "f":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: find <key>")
+		}
+		data, found := r.tree.Find(args[0])
+		if !found {
+			return fmt.Errorf("%s not found", args[0])
+		}
+		fmt.Fprintf(r.out, "%s: %s\n", args[0], data)
+	case "dump":
+		r.dump()
+	case "pretty":
+		r.tree.prettyPrint(r.out)
+	case "undo", "u":
+		if len(r.undo) == 0 {
+			return fmt.Errorf("nothing to undo")
+		}
+		u := r.undo[len(r.undo)-1]
+		r.undo = r.undo[:len(r.undo)-1]
+		r.tree.Root = u.root.node()
+		fmt.Fprintf(r.out, "undo %s\n", u.cmd)
+		r.dump()
+	case "history", "h":
+		for i, c := range r.history {
+			fmt.Fprintf(r.out, "%4d  %s\n", i+1, c)
+		}
+	case "help", "?":
+		fmt.Fprint(r.out, replHelp)
+	case "quit", "exit", "q":
+		return errQuit
+	default:
+		return fmt.Errorf("unknown command %s, try help", name)
+	}
+	return nil
+}
+
+const replHelp = `insert <key> [<data>]   insert or update a key
+find <key>              look up a key
+delete <key>            delete a key
+dump                    print the tree with [balance,height] per node
+pretty                  print the tree turned by 90°
+undo                    revert the last insert or delete (up to 20 times)
+history                 list the commands entered so far
+!!, !<n>                repeat the last or the n-th command
+quit                    leave
+`
+
+// `recall` returns the command that `!!` or `!<n>` refers to.
+func (r *REPL) recall(cmd string) (string, error) {
+	i := len(r.history)
+	if cmd != "!!" {
+		n, err := strconv.Atoi(cmd[1:])
+		if err != nil {
+			return "", fmt.Errorf("usage: !! or !<n>")
+		}
+		i = n
+	}
+	if i < 1 || i > len(r.history) {
+		return "", fmt.Errorf("no command %d in history", i)
+	}
+	return r.history[i-1], nil
+}
+
+// `change` runs an insert or delete, saves the previous tree for `undo`,
+// and prints the rotations that fired and the resulting tree.
+func (r *REPL) change(cmd string, f func()) {
+	if len(r.undo) == maxUndo {
+		r.undo = append(r.undo[:0], r.undo[1:]...)
+	}
+	r.undo = append(r.undo, undoEntry{cmd, r.tree.Root.snapshot()})
+	// The rotations come from the tree's recorder. If the tree has none, use one just for
+	// this command, so that the steps do not pile up.
+	if r.tree.Recorder == nil {
+		r.tree.Recorder = &Recorder{}
+		defer func() { r.tree.Recorder = nil }()
+	}
+	n := len(r.tree.Recorder.Steps)
+	f()
+	step := r.tree.Recorder.Steps[n]
+	if len(step.Rotations) == 0 {
+		fmt.Fprintln(r.out, "no rotations")
+	}
+	for _, rot := range step.Rotations {
+		fmt.Fprintf(r.out, "%s%s %s\n", strings.Repeat("  ", rot.Depth), rot.Kind, rot.Node)
+	}
+	r.dump()
+}
+
+func (r *REPL) dump() {
+	if r.tree.Root == nil {
+		fmt.Fprintln(r.out, "(empty)")
+		return
+	}
+	r.tree.Root.snapshot().Dump(r.out, 0, "")
+}
+
+// `cmdREPL` starts the REPL. With `-script`, it runs the commands from a file instead of the terminal.
+// With `-db`, it starts from a saved snapshot rather than from an empty tree.
+func cmdREPL(fs *flag.FlagSet, db *string, args []string, w io.Writer) error {
+	script := fs.String("script", "", "read commands from `file`")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	t, err := openOptionalDB(fs, db)
+	if err != nil {
+		return err
+	}
+	r := NewREPL(t, w)
+	if *script == "" {
+		return r.Run(os.Stdin, true)
+	}
+	f, err := os.Open(*script)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.Run(f, false)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestREPL(t *testing.T) {
+	var out bytes.Buffer
+	r := NewREPL(nil, &out)
+	script := `# A right-left case.
+insert a alpha
+insert c charlie
+insert b bravo two
+find b
+history
+`
+	if err := r.Run(strings.NewReader(script), false); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"> insert b bravo two\nrotateRightLeft a\n  rotateRight c\n  rotateLeft a\nb[0,2]\n",
+		"> find b\nb: bravo two\n",
+		"   3  insert b bravo two\n   4  find b\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := r.Exec("undo"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "undo insert b bravo two\na[1,2]\n+R--c[0,1]\n"; out.String() != want {
+		t.Errorf("undo printed %q, want %q", out.String(), want)
+	}
+	if err := r.Exec("!1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range []string{"undo", "undo", "undo"} {
+		if err := r.Exec(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.tree.Root != nil {
+		t.Errorf("tree is not empty after undoing all inserts")
+	}
+	for _, cmd := range []string{"undo", "delete a", "find a", "insert", "frobnicate", "!99", "!x", "", "   "} {
+		if err := r.Exec(cmd); err == nil {
+			t.Errorf("%s did not fail", cmd)
+		}
+	}
+}
+
+func TestREPLRecorderAndUndoLimit(t *testing.T) {
+	var out bytes.Buffer
+	rec := &Recorder{}
+	r := NewREPL(&Tree{Recorder: rec}, &out)
+	for i := 0; i < maxUndo+5; i++ {
+		if err := r.Exec(fmt.Sprintf("insert %02d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The REPL uses the tree's own recorder and leaves it in place.
+	if r.tree.Recorder != rec || len(rec.Steps) != maxUndo+5 {
+		t.Errorf("the tree's recorder has been replaced or has missed steps")
+	}
+	for i := 0; i < maxUndo; i++ {
+		if err := r.Exec("undo"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Exec("undo"); err == nil {
+		t.Errorf("undo beyond the limit did not fail")
+	}
+	if n := r.tree.Len(); n != 5 {
+		t.Errorf("%d keys left after undoing, want 5", n)
+	}
+}
+
+func TestREPLInteractive(t *testing.T) {
+	var out bytes.Buffer
+	r := NewREPL(nil, &out)
+	// In interactive mode, errors do not stop the REPL, and `quit` ends it.
+	if err := r.Run(strings.NewReader("delete x\ninsert x\ndelete x\nquit\ninsert y\n"), true); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "error: x not found") {
+		t.Errorf("error was not printed:\n%s", out.String())
+	}
+	if r.tree.Root != nil {
+		t.Errorf("commands after quit have been executed")
+	}
+}
+
+func TestCLIREPLScript(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.txt")
+	if err := os.WriteFile(script, []byte("insert a\ndelete b\ninsert c\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	err := runCommand([]string{"repl", "-script", script}, &out)
+	if err == nil || !strings.Contains(err.Error(), "line 2: b not found") {
+		t.Errorf("script returned %v", err)
+	}
+	if strings.Contains(out.String(), "insert c") {
+		t.Errorf("script continued after an error:\n%s", out.String())
+	}
+}
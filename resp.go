@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// `RESPServer` serves a `Tree` over the Redis protocol (RESP), so that redis-cli and Redis client
+// libraries can query it. It implements a small subset of the Redis commands:
+//
+//	PING [message]
+//	GET key
+//	SET key value
+//	DEL key [key ...]
+//	EXISTS key [key ...]
+//	DBSIZE
+//	ZRANGEBYLEX name min max [LIMIT offset count]
+//	ZLEXCOUNT name min max
+//
+// For the sorted-set commands, the whole tree acts as a single sorted set whose members are the
+// keys. `name` is required for compatibility with Redis, but ignored. `min` and `max` use the
+// Redis syntax: "[a" includes a, "(a" excludes a, "-" and "+" are the smallest and the largest key.
+//
+// Besides RESP arrays, the server also accepts inline commands, so a plain telnet session works, too.
+// Like `Server`, a `RESPServer` is safe for concurrent clients.
+type RESPServer struct {
+	mu   sync.RWMutex
+	tree *Tree
+	// `size` is the number of keys, so that DBSIZE does not need to count them.
+	size int
+}
+
+const (
+	// Limits for the requests of a client, to keep a broken client from exhausting the memory.
+	maxRESPArgs    = 1 << 16
+	maxRESPBulkLen = 1 << 24
+	// Inline commands and the headers of arrays and bulk strings are lines. Redis allows 64 KiB, too.
+	maxRESPLineLen = 64 << 10
+)
+
+// `NewRESPServer` creates a server for `t`. If `t` is nil, the server starts with an empty tree.
+func NewRESPServer(t *Tree) *RESPServer {
+	if t == nil {
+		t = &Tree{}
+	}
+	return &RESPServer{tree: t, size: t.Len()}
+}
+
+// `Serve` accepts connections on `l` and serves each one in its own goroutine.
+// It returns when `l` is closed.
+func (s *RESPServer) Serve(l net.Listener) error {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer c.Close()
+			s.ServeConn(c)
+		}()
+	}
+}
+
+// `ServeConn` reads commands from `c` and writes the replies back, until the client
+// closes the connection or sends invalid RESP data.
+func (s *RESPServer) ServeConn(c io.ReadWriter) error {
+	r := bufio.NewReader(c)
+	w := bufio.NewWriter(c)
+	for {
+		args, err := readCommand(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// After a protocol error, the position in the stream is unknown. Like Redis, give up.
+			writeError(w, "ERR Protocol error: "+err.Error())
+			w.Flush()
+			return err
+		}
+		if len(args) > 0 {
+			s.exec(w, args)
+		}
+		// Flush only when the client has no further commands in the pipeline.
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// `readCommand` reads a RESP array of bulk strings, or an inline command.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n > maxRESPArgs {
+		return nil, fmt.Errorf("invalid multibulk length")
+	}
+	args := make([]string, 0, max(n, 0))
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("expected '$', got '%.1s'", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxRESPBulkLen {
+			return nil, fmt.Errorf("invalid bulk length")
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if string(buf[size:]) != "\r\n" {
+			return nil, fmt.Errorf("bulk string not terminated by CRLF")
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+// `readLine` reads a line and strips the CRLF (or a single LF, which inline commands may end with).
+// Lines longer than `maxRESPLineLen` are an error.
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		// `chunk` points into the reader's buffer, so it must be copied before the next read.
+		line = append(line, chunk...)
+		if len(line) > maxRESPLineLen {
+			return "", fmt.Errorf("line too long")
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				return "", io.ErrUnexpectedEOF
+			}
+			return "", err
+		}
+		return strings.TrimSuffix(strings.TrimSuffix(string(line), "\n"), "\r"), nil
+	}
+}
+
+// `unexpectedEOF` turns EOF in the middle of a command into `io.ErrUnexpectedEOF`.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// The `write...` functions encode the RESP reply types.
+func writeSimple(w *bufio.Writer, s string) { fmt.Fprintf(w, "+%s\r\n", s) }
+func writeError(w *bufio.Writer, s string)  { fmt.Fprintf(w, "-%s\r\n", s) }
+func writeInt(w *bufio.Writer, n int)       { fmt.Fprintf(w, ":%d\r\n", n) }
+func writeBulk(w *bufio.Writer, s string)   { fmt.Fprintf(w, "$%d\r\n%s\r\n", len(s), s) }
+func writeNull(w *bufio.Writer)             { w.WriteString("$-1\r\n") }
+
+func writeArray(w *bufio.Writer, items []string) {
+	fmt.Fprintf(w, "*%d\r\n", len(items))
+	for _, it := range items {
+		writeBulk(w, it)
+	}
+}
+
+// `respArity` is the number of arguments of each command, including the command name.
+// A negative number -n means at least n.
+var respArity = map[string]int{
+	"PING":        -1,
+	"GET":         2,
+	"SET":         3,
+	"DEL":         -2,
+	"EXISTS":      -2,
+	"DBSIZE":      1,
+	"ZRANGEBYLEX": -4,
+	"ZLEXCOUNT":   4,
+	"COMMAND":     -1,
+}
+
+// `exec` runs a single command and writes its reply.
+func (s *RESPServer) exec(w *bufio.Writer, args []string) {
+	name := strings.ToUpper(args[0])
+	arity, ok := respArity[name]
+	if !ok {
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", args[0]))
+		return
+	}
+	if (arity > 0 && len(args) != arity) || len(args) < -arity {
+		writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+		return
+	}
+
+	switch name {
+	case "PING":
+		switch len(args) {
+		case 1:
+			writeSimple(w, "PONG")
+		case 2:
+			writeBulk(w, args[1])
+		default:
+			writeError(w, "ERR wrong number of arguments for 'ping' command")
+		}
+	case "COMMAND":
+		// redis-cli asks for the command documentation on startup. An empty reply is fine.
+		writeArray(w, nil)
+	case "GET":
+		s.mu.RLock()
+		data, found := s.tree.Find(args[1])
+		s.mu.RUnlock()
+		if !found {
+			writeNull(w)
+			return
+		}
+		writeBulk(w, data)
+	case "SET":
+		s.mu.Lock()
+		if _, replaced := s.tree.Upsert(args[1], args[2]); !replaced {
+			s.size++
+		}
+		s.mu.Unlock()
+		writeSimple(w, "OK")
+	case "DEL":
+		n := 0
+		s.mu.Lock()
+		for _, k := range args[1:] {
+			if s.tree.Delete(k) {
+				n++
+			}
+		}
+		s.size -= n
+		s.mu.Unlock()
+		writeInt(w, n)
+	case "EXISTS":
+		// As in Redis, a key that is given twice counts twice.
+		n := 0
+		s.mu.RLock()
+		for _, k := range args[1:] {
+			if _, found := s.tree.Find(k); found {
+				n++
+			}
+		}
+		s.mu.RUnlock()
+		writeInt(w, n)
+	case "DBSIZE":
+		s.mu.RLock()
+		n := s.size
+		s.mu.RUnlock()
+		writeInt(w, n)
+	case "ZRANGEBYLEX", "ZLEXCOUNT":
+		s.lexRange(w, name, args[2:])
+	}
+}
+
+// `lexBound` is a parsed `min` or `max` argument of ZRANGEBYLEX.
+type lexBound struct {
+	value     string
+	inclusive bool
+	// `infinite` is set for "-" and "+".
+	infinite bool
+}
+
+func parseLexBound(s string) (lexBound, bool) {
+	switch {
+	case s == "-" || s == "+":
+		return lexBound{infinite: true}, true
+	case strings.HasPrefix(s, "["):
+		return lexBound{value: s[1:], inclusive: true}, true
+	case strings.HasPrefix(s, "("):
+		return lexBound{value: s[1:]}, true
+	}
+	return lexBound{}, false
+}
+
+// `lexRange` implements ZRANGEBYLEX and ZLEXCOUNT. `args` starts at `min`.
+func (s *RESPServer) lexRange(w *bufio.Writer, name string, args []string) {
+	lo, ok1 := parseLexBound(args[0])
+	hi, ok2 := parseLexBound(args[1])
+	switch {
+	case !ok1 || !ok2:
+		writeError(w, "ERR min or max not valid string range item")
+	case args[0] == "+" || args[1] == "-":
+		// "+" as min or "-" as max make an empty range.
+		writeEmptyLexRange(w, name)
+	default:
+		s.writeLexRange(w, name, lo, hi, args[2:])
+	}
+}
+
+func writeEmptyLexRange(w *bufio.Writer, name string) {
+	if name == "ZLEXCOUNT" {
+		writeInt(w, 0)
+		return
+	}
+	writeArray(w, nil)
+}
+
+func (s *RESPServer) writeLexRange(w *bufio.Writer, name string, lo, hi lexBound, opts []string) {
+	offset, count := 0, -1
+	switch {
+	case len(opts) == 0:
+	case len(opts) == 3 && name == "ZRANGEBYLEX" && strings.EqualFold(opts[0], "LIMIT"):
+		var err1, err2 error
+		offset, err1 = strconv.Atoi(opts[1])
+		count, err2 = strconv.Atoi(opts[2])
+		if err1 != nil || err2 != nil {
+			writeError(w, "ERR value is not an integer or out of range")
+			return
+		}
+	default:
+		writeError(w, "ERR syntax error")
+		return
+	}
+	// Redis returns nothing for a negative offset, and everything for a negative count.
+	if offset < 0 || count == 0 {
+		writeEmptyLexRange(w, name)
+		return
+	}
+
+	var keys []string
+	n := 0
+	s.mu.RLock()
+	// `Range` includes `from`, so an exclusive lower bound skips it below.
+	s.tree.Range(lo.value, "", func(node *Node) bool {
+		v := node.Value
+		if !lo.infinite && !lo.inclusive && v == lo.value {
+			return true
+		}
+		if !hi.infinite && (v > hi.value || (!hi.inclusive && v == hi.value)) {
+			return false
+		}
+		n++
+		// ZLEXCOUNT has no offset and no count, and needs no keys, only `n`.
+		if n <= offset || name == "ZLEXCOUNT" {
+			return true
+		}
+		keys = append(keys, v)
+		return count < 0 || len(keys) < count
+	})
+	s.mu.RUnlock()
+
+	if name == "ZLEXCOUNT" {
+		writeInt(w, n)
+		return
+	}
+	writeArray(w, keys)
+}
+
+// `cmdRESP` serves a tree over the Redis protocol until the process is stopped.
+// With `-db`, it starts from a saved snapshot.
+func cmdRESP(fs *flag.FlagSet, db *string, args []string, w io.Writer) error {
+	addr := fs.String("addr", "localhost:6379", "listen `address`")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	t, err := openOptionalDB(fs, db)
+	if err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "listening on %s\n", l.Addr())
+	return NewRESPServer(t).Serve(l)
+}
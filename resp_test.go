@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// `respClient` sends commands as RESP arrays and decodes the replies into strings:
+// "+OK", "-ERR ...", ":3", "nil", bulk strings as they are, and arrays as "[a b c]".
+type respClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func startRESPServer(t *testing.T, tree *Tree) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() { done <- NewRESPServer(tree).Serve(l) }()
+	t.Cleanup(func() {
+		l.Close()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return l.Addr().String()
+}
+
+func dialRESP(t *testing.T, addr string) *respClient {
+	t.Helper()
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return &respClient{c, bufio.NewReader(c)}
+}
+
+func (c *respClient) do(args ...string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return "", err
+	}
+	return c.reply()
+}
+
+func (c *respClient) reply() (string, error) {
+	line, err := readLine(c.r)
+	if err != nil {
+		return "", err
+	}
+	switch line[0] {
+	case '+', '-', ':':
+		return line, nil
+	case '$':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return "nil", nil
+		}
+		buf := make([]byte, n+2)
+		_, err := io.ReadFull(c.r, buf)
+		return string(buf[:n]), err
+	case '*':
+		n, _ := strconv.Atoi(line[1:])
+		items := make([]string, n)
+		for i := range items {
+			if items[i], err = c.reply(); err != nil {
+				return "", err
+			}
+		}
+		return "[" + strings.Join(items, " ") + "]", nil
+	}
+	return "", fmt.Errorf("unexpected reply %q", line)
+}
+
+func TestRESPServer(t *testing.T) {
+	c := dialRESP(t, startRESPServer(t, nil))
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"PING"}, "+PONG"},
+		{[]string{"ping", "hi there"}, "hi there"},
+		{[]string{"GET", "a"}, "nil"},
+		{[]string{"SET", "a", "alpha"}, "+OK"},
+		{[]string{"SET", "a", "alpha\r\ntwo"}, "+OK"},
+		{[]string{"GET", "a"}, "alpha\r\ntwo"},
+		{[]string{"SET", "b", ""}, "+OK"},
+		{[]string{"GET", "b"}, ""},
+		{[]string{"SET", "c", "charlie"}, "+OK"},
+		{[]string{"SET", "d", "delta"}, "+OK"},
+		{[]string{"DBSIZE"}, ":4"},
+		{[]string{"EXISTS", "a", "a", "x"}, ":2"},
+		{[]string{"ZRANGEBYLEX", "keys", "-", "+"}, "[a b c d]"},
+		{[]string{"ZRANGEBYLEX", "keys", "[b", "(d"}, "[b c]"},
+		{[]string{"ZRANGEBYLEX", "keys", "(b", "[d"}, "[c d]"},
+		{[]string{"ZRANGEBYLEX", "keys", "-", "+", "LIMIT", "1", "2"}, "[b c]"},
+		{[]string{"ZRANGEBYLEX", "keys", "-", "+", "limit", "3", "-1"}, "[d]"},
+		{[]string{"ZRANGEBYLEX", "keys", "+", "-"}, "[]"},
+		{[]string{"ZRANGEBYLEX", "keys", "a", "+"}, "-ERR min or max not valid string range item"},
+		{[]string{"ZRANGEBYLEX", "keys", "-", "+", "LIMIT", "x", "1"}, "-ERR value is not an integer or out of range"},
+		{[]string{"ZRANGEBYLEX", "keys", "-", "+", "LIMIT"}, "-ERR syntax error"},
+		{[]string{"ZLEXCOUNT", "keys", "(a", "+"}, ":3"},
+		{[]string{"DEL", "a", "b", "x"}, ":2"},
+		{[]string{"DBSIZE"}, ":2"},
+		{[]string{"GET"}, "-ERR wrong number of arguments for 'get' command"},
+		{[]string{"FLUSHALL"}, "-ERR unknown command 'FLUSHALL'"},
+	}
+	for _, tt := range tests {
+		got, err := c.do(tt.args...)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.args, err)
+		}
+		if got != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestRESPServerInlineAndPipelining(t *testing.T) {
+	c := dialRESP(t, startRESPServer(t, nil))
+	// Inline commands, all sent at once.
+	io.WriteString(c.conn, "SET k v\r\nGET k\nDBSIZE\r\n")
+	for _, want := range []string{"+OK", "v", ":1"} {
+		if got, err := c.reply(); err != nil || got != want {
+			t.Errorf("got %q, %v, want %q", got, err, want)
+		}
+	}
+	// A protocol error closes the connection.
+	io.WriteString(c.conn, "*1\r\n$x\r\n")
+	if got, _ := c.reply(); !strings.HasPrefix(got, "-ERR Protocol error") {
+		t.Errorf("got %q after a protocol error", got)
+	}
+	if _, err := c.reply(); err != io.EOF {
+		t.Errorf("connection is still open: %v", err)
+	}
+}
+
+func TestRESPServerLongLine(t *testing.T) {
+	// An endless line must not make the server buffer it all.
+	in := strings.NewReader("GET " + strings.Repeat("k", 1<<20))
+	var out strings.Builder
+	err := NewRESPServer(nil).ServeConn(struct {
+		io.Reader
+		io.Writer
+	}{in, &out})
+	if err == nil || !strings.HasPrefix(out.String(), "-ERR Protocol error: line too long") {
+		t.Errorf("got %q, %v", out.String(), err)
+	}
+	if in.Len() == 0 {
+		t.Errorf("server has read the whole line")
+	}
+}
+
+func TestRESPServerConcurrent(t *testing.T) {
+	tree := &Tree{}
+	tree.Insert("existing", "")
+	addr := startRESPServer(t, tree)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		c := dialRESP(t, addr)
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				key := fmt.Sprintf("g%d-%02d", g, i)
+				if _, err := c.do("SET", key, "x"); err != nil {
+					t.Error(err)
+					return
+				}
+				if i%2 == 0 {
+					c.do("DEL", key)
+				}
+				c.do("ZRANGEBYLEX", "keys", "-", "+", "LIMIT", "0", "5")
+			}
+		}(g)
+	}
+	wg.Wait()
+	if got, _ := dialRESP(t, addr).do("DBSIZE"); got != fmt.Sprintf(":%d", 8*25+1) {
+		t.Errorf("DBSIZE returned %s", got)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"math"
+	"os"
+)
+
+// `ScapegoatTree` is a binary search tree that needs no balance information in its nodes at all.
+//
+// Nodes are inserted as in an unbalanced binary search tree. Only if a new node ends up
+// deeper than log(n) to the base 1/alpha, the tree walks back up the insert path to find
+// a "scapegoat": an ancestor whose subtree is badly unbalanced in terms of size. That subtree
+// is then rebuilt into a perfectly balanced one. Rebuilding is expensive, but it happens
+// rarely enough that inserts take O(log n) time amortized.
+//
+// The zero value is an empty tree with alpha = 0.7.
+type ScapegoatTree struct {
+	Root  *ScapegoatNode
+	alpha float64
+	size  int
+}
+
+// `ScapegoatNode` is a node of a `ScapegoatTree`. Note the missing height or size field.
+type ScapegoatNode struct {
+	Value string
+	Data  string
+	Left  *ScapegoatNode
+	Right *ScapegoatNode
+}
+
+// `NewScapegoatTree` creates an empty tree. `alpha` must be between 0.5 and 1; smaller values
+// keep the tree more strictly balanced but cause more rebuilds.
+func NewScapegoatTree(alpha float64) *ScapegoatTree {
+	if alpha <= 0.5 || alpha >= 1 {
+		alpha = 0.7
+	}
+	return &ScapegoatTree{alpha: alpha}
+}
+
+func (t *ScapegoatTree) getAlpha() float64 {
+	if t.alpha == 0 {
+		return 0.7
+	}
+	return t.alpha
+}
+
+// `maxDepth` is the depth that a node in a tree of `n` nodes may have before a rebuild is required.
+func (t *ScapegoatTree) maxDepth(n int) int {
+	return int(math.Log(float64(n)) / math.Log(1/t.getAlpha()))
+}
+
+// `size` counts the nodes of a subtree. Since nodes do not store sizes, this takes O(size) steps.
+func (n *ScapegoatNode) size() int {
+	if n == nil {
+		return 0
+	}
+	return n.Left.size() + n.Right.size() + 1
+}
+
+// `Len` returns the number of values in the tree.
+func (t *ScapegoatTree) Len() int {
+	return t.size
+}
+
+// `Insert` inserts a value or updates the data of an existing one.
+func (t *ScapegoatTree) Insert(value, data string) {
+	var path []*ScapegoatNode
+	n := t.Root
+	for n != nil {
+		if value == n.Value {
+			n.Data = data
+			return
+		}
+		path = append(path, n)
+		if value < n.Value {
+			n = n.Left
+		} else {
+			n = n.Right
+		}
+	}
+	n = &ScapegoatNode{Value: value, Data: data}
+	t.size++
+	if len(path) == 0 {
+		t.Root = n
+		return
+	}
+	if p := path[len(path)-1]; value < p.Value {
+		p.Left = n
+	} else {
+		p.Right = n
+	}
+	if len(path) <= t.maxDepth(t.size) {
+		return
+	}
+
+	// The new node is too deep. Walk up the path and find the lowest ancestor whose
+	// child on the path holds more than alpha times the ancestor's subtree size.
+	// The size of the child on the path is known from the previous step, so only the sibling
+	// subtree needs to be counted. The scapegoat's subtree gets rebuilt anyway, so the counting
+	// costs no more than the rebuild.
+	alpha := t.getAlpha()
+	child, childSize := n, 1
+	for i := len(path) - 1; i >= 0; i-- {
+		sibling := path[i].Left
+		if sibling == child {
+			sibling = path[i].Right
+		}
+		size := childSize + 1 + sibling.size()
+		if float64(childSize) > alpha*float64(size) {
+			rebuilt := path[i].rebuild(size)
+			switch {
+			case i == 0:
+				t.Root = rebuilt
+			case path[i-1].Left == path[i]:
+				path[i-1].Left = rebuilt
+			default:
+				path[i-1].Right = rebuilt
+			}
+			return
+		}
+		child, childSize = path[i], size
+	}
+}
+
+// `rebuild` turns the subtree at `n` into a perfectly balanced tree.
+func (n *ScapegoatNode) rebuild(size int) *ScapegoatNode {
+	nodes := make([]*ScapegoatNode, 0, size)
+	var flatten func(*ScapegoatNode)
+	flatten = func(n *ScapegoatNode) {
+		if n == nil {
+			return
+		}
+		flatten(n.Left)
+		nodes = append(nodes, n)
+		flatten(n.Right)
+	}
+	flatten(n)
+
+	var build func([]*ScapegoatNode) *ScapegoatNode
+	build = func(ns []*ScapegoatNode) *ScapegoatNode {
+		if len(ns) == 0 {
+			return nil
+		}
+		m := len(ns) / 2
+		ns[m].Left = build(ns[:m])
+		ns[m].Right = build(ns[m+1:])
+		return ns[m]
+	}
+	return build(nodes)
+}
+
+// `Find` returns the data stored for `value`.
+func (t *ScapegoatTree) Find(value string) (string, bool) {
+	n := t.Root
+	for n != nil {
+		switch {
+		case value == n.Value:
+			return n.Data, true
+		case value < n.Value:
+			n = n.Left
+		default:
+			n = n.Right
+		}
+	}
+	return "", false
+}
+
+// `Walk` calls `f` for each value and its data in sort order.
+func (t *ScapegoatTree) Walk(f func(value, data string)) {
+	var walk func(*ScapegoatNode)
+	walk = func(n *ScapegoatNode) {
+		if n == nil {
+			return
+		}
+		walk(n.Left)
+		f(n.Value, n.Data)
+		walk(n.Right)
+	}
+	walk(t.Root)
+}
+
+// `Dump` prints the tree like `Tree.Dump`, without balance and height.
+func (t *ScapegoatTree) Dump() {
+	dumpTree(os.Stdout, t.Root, 0, "", func(n *ScapegoatNode) (string, *ScapegoatNode, *ScapegoatNode) {
+		return n.Value, n.Left, n.Right
+	})
+}
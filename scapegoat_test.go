@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+// checkDepth verifies the order of the tree, its size, and that no node is deeper than
+// maxDepth allows. Note that the depth bound is checked against the current size,
+// which is a slightly stricter condition than the one Insert maintains after a rebuild.
+func (t *ScapegoatTree) checkDepth(tb testing.TB) {
+	var check func(n *ScapegoatNode, depth int, lo, hi *string) (int, int)
+	check = func(n *ScapegoatNode, depth int, lo, hi *string) (size, maxDepth int) {
+		if n == nil {
+			return 0, depth - 1
+		}
+		if (lo != nil && n.Value <= *lo) || (hi != nil && n.Value >= *hi) {
+			tb.Fatalf("node %s is out of order", n.Value)
+		}
+		ls, ld := check(n.Left, depth+1, lo, &n.Value)
+		rs, rd := check(n.Right, depth+1, &n.Value, hi)
+		return ls + rs + 1, max(depth, max(ld, rd))
+	}
+	size, depth := check(t.Root, 0, nil, nil)
+	if size != t.size {
+		tb.Fatalf("tree has %d nodes, Len() is %d", size, t.size)
+	}
+	if size > 0 && depth > t.maxDepth(size) {
+		tb.Fatalf("tree with %d nodes has depth %d, more than %d", size, depth, t.maxDepth(size))
+	}
+}
+
+func TestScapegoatTree_ascending(t *testing.T) {
+	// Ascending values would turn an unbalanced tree into a list.
+	st := NewScapegoatTree(0.7)
+	for i := 0; i < 1000; i++ {
+		st.Insert(strconv.Itoa(100000+i), "")
+		st.checkDepth(t)
+	}
+}
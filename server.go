@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// `Server` serves a `Tree` over HTTP, as a small key-value index:
+//
+//	GET    /keys/{k}                     the data of key k as text/plain, or 404
+//	PUT    /keys/{k}                     set the data of key k to the request body (201 if k is new, 204 otherwise)
+//	DELETE /keys/{k}                     delete key k (204, or 404 if k does not exist)
+//	GET    /range?from=&to=&limit=       up to `limit` keys with from <= key < to, as JSON
+//	GET    /range?cursor=                the next page of a range
+//	GET    /debug/tree                   the tree structure in the format of `Tree.Dump`
+//	GET    /debug/tree?format=dot        the tree structure in the DOT language, see `Tree.WriteDOT`
+//
+// Keys are the unescaped URL path after /keys/ and may contain slashes.
+//
+// A `Server` is safe for concurrent requests. Reads share a lock, changes take it exclusively.
+type Server struct {
+	mu   sync.RWMutex
+	tree *Tree
+	mux  *http.ServeMux
+}
+
+const (
+	defaultRangeLimit = 100
+	maxRangeLimit     = 1000
+	// `maxDataSize` limits the request body of a PUT.
+	maxDataSize = 1 << 20
+)
+
+// `NewServer` creates a server for `t`. If `t` is nil, the server starts with an empty tree.
+func NewServer(t *Tree) *Server {
+	if t == nil {
+		t = &Tree{}
+	}
+	s := &Server{tree: t, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/keys/", s.handleKey)
+	s.mux.HandleFunc("/range", s.handleRange)
+	s.mux.HandleFunc("/debug/tree", s.handleDebugTree)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// `allow` replies with 405 Method Not Allowed unless the request uses one of the `methods`.
+func allow(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+func (s *Server) handleKey(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet, http.MethodPut, http.MethodDelete) {
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/keys/")
+	if key == "" {
+		http.Error(w, "missing key", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.RLock()
+		data, found := s.tree.Find(key)
+		s.mu.RUnlock()
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, data)
+	case http.MethodPut:
+		// Read the body before taking the lock; a slow client must not block other requests.
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxDataSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		s.mu.Lock()
+		_, replaced := s.tree.Upsert(key, string(body))
+		s.mu.Unlock()
+		if replaced {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case http.MethodDelete:
+		s.mu.Lock()
+		deleted := s.tree.Delete(key)
+		s.mu.Unlock()
+		if !deleted {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// `rangeItem` and `rangePage` are the JSON response of /range.
+type rangeItem struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type rangePage struct {
+	Items []rangeItem `json:"items"`
+	// `Next` is the cursor for the next page. It is empty on the last page.
+	Next string `json:"next,omitempty"`
+}
+
+// `rangeCursor` is the state of a range query at the start of the next page: the first key
+// of that page, the end of the range, and the page size. Starting at a key rather than at
+// an offset makes the page start at the right place even if keys have been inserted or deleted
+// since the previous page. The cursor is encoded to keep clients from relying on its contents.
+type rangeCursor struct {
+	From  string `json:"f"`
+	To    string `json:"t"`
+	Limit int    `json:"l"`
+}
+
+func (c rangeCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (rangeCursor, error) {
+	var c rangeCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(b, &c) != nil || c.Limit < 1 || c.Limit > maxRangeLimit {
+		return c, fmt.Errorf("invalid cursor")
+	}
+	return c, nil
+}
+
+// `rangeQuery` reads the range from the query parameters, or from the cursor alone.
+func rangeQuery(q url.Values) (rangeCursor, error) {
+	if c := q.Get("cursor"); c != "" {
+		if q.Get("from") != "" || q.Get("to") != "" || q.Get("limit") != "" {
+			return rangeCursor{}, fmt.Errorf("cursor cannot be combined with from, to, or limit")
+		}
+		return decodeCursor(c)
+	}
+	rc := rangeCursor{From: q.Get("from"), To: q.Get("to"), Limit: defaultRangeLimit}
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > maxRangeLimit {
+			return rc, fmt.Errorf("limit must be between 1 and %d", maxRangeLimit)
+		}
+		rc.Limit = n
+	}
+	return rc, nil
+}
+
+func (s *Server) handleRange(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	rc, err := rangeQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page := rangePage{Items: []rangeItem{}}
+	s.mu.RLock()
+	// Fetch one item more than requested to find out whether there is a next page.
+	s.tree.Range(rc.From, rc.To, func(n *Node) bool {
+		if len(page.Items) == rc.Limit {
+			next := rc
+			next.From = n.Value
+			page.Next = next.encode()
+			return false
+		}
+		page.Items = append(page.Items, rangeItem{n.Value, n.Data})
+		return true
+	})
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (s *Server) handleDebugTree(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	switch f := r.URL.Query().Get("format"); f {
+	case "", "dump":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		s.tree.Root.snapshot().Dump(w, 0, "")
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		s.tree.WriteDOT(w)
+	default:
+		http.Error(w, fmt.Sprintf("unknown format %q", f), http.StatusBadRequest)
+	}
+}
+
+// `cmdServe` serves a tree over HTTP until the process is stopped. With `-db`, it starts from a saved snapshot.
+func cmdServe(fs *flag.FlagSet, db *string, args []string, w io.Writer) error {
+	addr := fs.String("addr", "localhost:8080", "listen `address`")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	t, err := openOptionalDB(fs, db)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "listening on %s\n", *addr)
+	return http.ListenAndServe(*addr, NewServer(t))
+}
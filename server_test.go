@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// `do` sends a request to `h` and returns the status code and the body of the response.
+func do(t *testing.T, h http.Handler, method, target, body string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec.Code, rec.Body.String()
+}
+
+func TestServerKeys(t *testing.T) {
+	s := NewServer(nil)
+	tests := []struct {
+		method, target, body string
+		code                 int
+		want                 string
+	}{
+		{"GET", "/keys/a", "", 404, ""},
+		{"PUT", "/keys/a", "alpha", 201, ""},
+		{"PUT", "/keys/a", "alpha2", 204, ""},
+		{"GET", "/keys/a", "", 200, "alpha2"},
+		{"PUT", "/keys/dir/file%20name", "slash", 201, ""},
+		{"GET", "/keys/dir/file%20name", "", 200, "slash"},
+		{"DELETE", "/keys/a", "", 204, ""},
+		{"DELETE", "/keys/a", "", 404, ""},
+		{"GET", "/keys/a", "", 404, ""},
+		{"GET", "/keys/", "", 400, ""},
+		{"POST", "/keys/a", "", 405, ""},
+		{"POST", "/range", "", 405, ""},
+	}
+	for _, tt := range tests {
+		code, body := do(t, s, tt.method, tt.target, tt.body)
+		if code != tt.code || (tt.want != "" && body != tt.want) {
+			t.Errorf("%s %s: %d %q, want %d %q", tt.method, tt.target, code, body, tt.code, tt.want)
+		}
+	}
+}
+
+func TestServerRange(t *testing.T) {
+	tree := &Tree{}
+	for i := 0; i < 25; i++ {
+		tree.Insert(fmt.Sprintf("k%02d", i), fmt.Sprint(i))
+	}
+	s := NewServer(tree)
+
+	// Page through k05 <= key < k20 with a page size of 4.
+	var keys []string
+	target := "/range?from=k05&to=k20&limit=4"
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("too many pages")
+		}
+		code, body := do(t, s, "GET", target, "")
+		if code != 200 {
+			t.Fatalf("GET %s: %d %s", target, code, body)
+		}
+		var page rangePage
+		if err := json.Unmarshal([]byte(body), &page); err != nil {
+			t.Fatal(err)
+		}
+		for _, it := range page.Items {
+			keys = append(keys, it.Key)
+		}
+		if page.Next == "" {
+			if pages != 3 {
+				t.Errorf("got %d pages, want 4", pages+1)
+			}
+			break
+		}
+		// The cursor alone defines the next page.
+		target = "/range?cursor=" + page.Next
+	}
+	if len(keys) != 15 || keys[0] != "k05" || keys[14] != "k19" {
+		t.Errorf("range returned %v", keys)
+	}
+
+	for _, q := range []string{"limit=0", "limit=x", "limit=100000", "cursor=!!!", "cursor=e30", "from=a&cursor=" + rangeCursor{Limit: 1}.encode()} {
+		if code, _ := do(t, s, "GET", "/range?"+q, ""); code != 400 {
+			t.Errorf("%s: status %d, want 400", q, code)
+		}
+	}
+	if _, body := do(t, s, "GET", "/range?from=z", ""); body != `{"items":[]}`+"\n" {
+		t.Errorf("empty range returned %s", body)
+	}
+}
+
+func TestServerDebugTree(t *testing.T) {
+	tree := &Tree{}
+	for _, v := range []string{"b", "a", "c"} {
+		tree.Insert(v, "")
+	}
+	s := NewServer(tree)
+	if _, body := do(t, s, "GET", "/debug/tree", ""); body != "b[0,2]\n+L--a[0,1]\n+R--c[0,1]\n" {
+		t.Errorf("dump returned %q", body)
+	}
+	if _, body := do(t, s, "GET", "/debug/tree?format=dot", ""); !strings.HasPrefix(body, "digraph tree {") {
+		t.Errorf("dot returned %q", body)
+	}
+	if code, _ := do(t, s, "GET", "/debug/tree?format=svg", ""); code != 400 {
+		t.Errorf("unknown format returned %d", code)
+	}
+}
+
+// Run with -race to check the locking.
+func TestServerConcurrent(t *testing.T) {
+	srv := httptest.NewServer(NewServer(nil))
+	defer srv.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				key := fmt.Sprintf("%s/keys/g%d-%02d", srv.URL, g, i)
+				req, _ := http.NewRequest("PUT", key, strings.NewReader("x"))
+				if resp, err := http.DefaultClient.Do(req); err == nil {
+					resp.Body.Close()
+				}
+				if resp, err := http.Get(srv.URL + "/range?limit=10"); err == nil {
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+				if i%2 == 0 {
+					req, _ := http.NewRequest("DELETE", key, nil)
+					if resp, err := http.DefaultClient.Do(req); err == nil {
+						resp.Body.Close()
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	resp, err := http.Get(srv.URL + "/range?limit=1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var page rangePage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 8*25 {
+		t.Errorf("%d keys left, want %d", len(page.Items), 8*25)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+// `SlabTree` is an AVL tree that stores all of its nodes in a single slice.
+//
+// With `Tree`, each `Node` is a separate heap object, and the garbage collector has to follow
+// every `Left` and `Right` pointer. `SlabTree` links its nodes by `int32` indices into the slice
+// instead, so the only pointers left are those inside the strings. Deleted nodes go onto a free list
+// and are reused by subsequent inserts.
+//
+// The zero value is an empty tree that is ready to use.
+type SlabTree struct {
+	// `nodes[0]` is the "nil" node. Its height is zero, so `height` needs no nil check.
+	nodes []slabNode
+	root  int32
+	free  int32
+	size  int
+}
+
+type slabNode struct {
+	value       string
+	data        string
+	left, right int32
+	// For nodes on the free list, `height` is -1 and `left` links to the next free node.
+	height int32
+}
+
+const slabNil int32 = 0
+
+// `NewSlabTree` creates a tree with room for `capacity` nodes.
+func NewSlabTree(capacity int) *SlabTree {
+	t := &SlabTree{}
+	t.nodes = make([]slabNode, 1, capacity+1)
+	return t
+}
+
+// `Len` returns the number of nodes in the tree.
+func (t *SlabTree) Len() int {
+	return t.size
+}
+
+func (t *SlabTree) height(i int32) int32 {
+	return t.nodes[i].height
+}
+
+func (t *SlabTree) bal(i int32) int32 {
+	return t.height(t.nodes[i].right) - t.height(t.nodes[i].left)
+}
+
+func (t *SlabTree) fixHeight(i int32) {
+	n := &t.nodes[i]
+	n.height = int32(max(int(t.height(n.left)), int(t.height(n.right)))) + 1
+}
+
+// `alloc` takes a node from the free list or appends a new one to the slab.
+func (t *SlabTree) alloc(value, data string) int32 {
+	if len(t.nodes) == 0 {
+		t.nodes = append(t.nodes, slabNode{})
+	}
+	n := slabNode{value: value, data: data, height: 1}
+	if t.free != slabNil {
+		i := t.free
+		t.free = t.nodes[i].left
+		t.nodes[i] = n
+		return i
+	}
+	t.nodes = append(t.nodes, n)
+	return int32(len(t.nodes) - 1)
+}
+
+// `release` puts a node onto the free list. The strings are cleared so that the
+// garbage collector can reclaim them.
+func (t *SlabTree) release(i int32) {
+	t.nodes[i] = slabNode{left: t.free, height: -1}
+	t.free = i
+}
+
+// `rotateLeft` and `rotateRight` work like their `Node` counterparts.
+func (t *SlabTree) rotateLeft(i int32) int32 {
+	r := t.nodes[i].right
+	t.nodes[i].right = t.nodes[r].left
+	t.nodes[r].left = i
+	t.fixHeight(i)
+	t.fixHeight(r)
+	return r
+}
+
+func (t *SlabTree) rotateRight(i int32) int32 {
+	l := t.nodes[i].left
+	t.nodes[i].left = t.nodes[l].right
+	t.nodes[l].right = i
+	t.fixHeight(i)
+	t.fixHeight(l)
+	return l
+}
+
+// `rebalance` also handles the case where the taller child is itself balanced.
+// This cannot happen after an insert, but it can happen after a delete.
+func (t *SlabTree) rebalance(i int32) int32 {
+	t.fixHeight(i)
+	switch b := t.bal(i); {
+	case b < -1:
+		if t.bal(t.nodes[i].left) > 0 {
+			t.nodes[i].left = t.rotateLeft(t.nodes[i].left)
+		}
+		return t.rotateRight(i)
+	case b > 1:
+		if t.bal(t.nodes[i].right) < 0 {
+			t.nodes[i].right = t.rotateRight(t.nodes[i].right)
+		}
+		return t.rotateLeft(i)
+	}
+	return i
+}
+
+// `Insert` inserts a new node or updates the data of an existing one, like `Tree.Insert`.
+func (t *SlabTree) Insert(value, data string) {
+	t.root = t.insert(t.root, value, data)
+}
+
+func (t *SlabTree) insert(i int32, value, data string) int32 {
+	if i == slabNil {
+		t.size++
+		return t.alloc(value, data)
+	}
+	n := &t.nodes[i]
+	switch {
+	case value == n.value:
+		n.data = data
+		return i
+	case value < n.value:
+		// `alloc` may grow the slab, so `n` must not be used after the recursive call.
+		l := t.insert(n.left, value, data)
+		t.nodes[i].left = l
+	default:
+		r := t.insert(n.right, value, data)
+		t.nodes[i].right = r
+	}
+	return t.rebalance(i)
+}
+
+// `Find` returns the data stored for `value`.
+func (t *SlabTree) Find(value string) (string, bool) {
+	if len(t.nodes) == 0 {
+		return "", false
+	}
+	i := t.root
+	for i != slabNil {
+		n := &t.nodes[i]
+		switch {
+		case value == n.value:
+			return n.data, true
+		case value < n.value:
+			i = n.left
+		default:
+			i = n.right
+		}
+	}
+	return "", false
+}
+
+// `Delete` removes `value` from the tree. It returns false if there was no such value.
+func (t *SlabTree) Delete(value string) bool {
+	if len(t.nodes) == 0 {
+		return false
+	}
+	size := t.size
+	t.root = t.delete(t.root, value)
+	return t.size < size
+}
+
+func (t *SlabTree) delete(i int32, value string) int32 {
+	if i == slabNil {
+		return slabNil
+	}
+	n := &t.nodes[i]
+	switch {
+	case value < n.value:
+		n.left = t.delete(n.left, value)
+	case value > n.value:
+		n.right = t.delete(n.right, value)
+	case n.left == slabNil || n.right == slabNil:
+		// At most one child: replace the node by that child.
+		child := n.left
+		if child == slabNil {
+			child = n.right
+		}
+		t.release(i)
+		t.size--
+		return child
+	default:
+		// Two children: move the smallest value of the right subtree into this node,
+		// then delete that value from the right subtree.
+		m := n.right
+		for t.nodes[m].left != slabNil {
+			m = t.nodes[m].left
+		}
+		n.value, n.data = t.nodes[m].value, t.nodes[m].data
+		n.right = t.delete(n.right, n.value)
+	}
+	return t.rebalance(i)
+}
+
+// `Traverse` calls `f` for each value and its data in sort order.
+func (t *SlabTree) Traverse(f func(value, data string)) {
+	if len(t.nodes) == 0 {
+		return
+	}
+	t.traverse(t.root, f)
+}
+
+func (t *SlabTree) traverse(i int32, f func(value, data string)) {
+	if i == slabNil {
+		return
+	}
+	t.traverse(t.nodes[i].left, f)
+	f(t.nodes[i].value, t.nodes[i].data)
+	t.traverse(t.nodes[i].right, f)
+}
+
+// `Height` returns the height of the tree.
+func (t *SlabTree) Height() int {
+	if len(t.nodes) == 0 {
+		return 0
+	}
+	return int(t.height(t.root))
+}
@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+// checkSlab verifies heights, balance, and order of the subtree at i,
+// and returns its height.
+func (t *SlabTree) checkSlab(tb testing.TB, i int32, lo, hi *string) int32 {
+	if i == slabNil {
+		return 0
+	}
+	n := t.nodes[i]
+	if (lo != nil && n.value <= *lo) || (hi != nil && n.value >= *hi) {
+		tb.Fatalf("node %s is out of order", n.value)
+	}
+	lh, rh := t.checkSlab(tb, n.left, lo, &n.value), t.checkSlab(tb, n.right, &n.value, hi)
+	if h := int32(max(int(lh), int(rh))) + 1; n.height != h {
+		tb.Fatalf("node %s has height %d, want %d", n.value, n.height, h)
+	}
+	if rh-lh < -1 || rh-lh > 1 {
+		tb.Fatalf("node %s is unbalanced", n.value)
+	}
+	return n.height
+}
+
+func TestSlabTree(t *testing.T) {
+	for _, tree := range trees {
+		t.Run(tree.name, func(t *testing.T) {
+			st := &SlabTree{}
+			for i := range tree.value {
+				st.Insert(tree.value[i], tree.data[i])
+			}
+			st.checkSlab(t, st.root, nil, nil)
+			want := newTree(tree)
+			var got []string
+			st.Traverse(func(v, d string) { got = append(got, v+d) })
+			i := 0
+			want.Traverse(want.Root, func(n *Node) {
+				if i >= len(got) || got[i] != n.Value+n.Data {
+					t.Fatalf("traversal differs from Tree at position %d", i)
+				}
+				i++
+			})
+			if i != len(got) || st.Len() != len(got) {
+				t.Errorf("SlabTree has %d nodes (Len: %d), Tree has %d", len(got), st.Len(), i)
+			}
+		})
+	}
+}
+
+func TestSlabTree_Delete(t *testing.T) {
+	st := NewSlabTree(100)
+	model := runModelTest(t, modelTest{
+		ops:   mapOps(st.Insert, st.Delete),
+		keys:  200,
+		steps: 5000,
+		check: func(tb testing.TB) { st.checkSlab(tb, st.root, nil, nil) },
+		walk:  st.Traverse,
+	})
+	if st.Len() != len(model) {
+		t.Fatalf("Len() = %d, want %d", st.Len(), len(model))
+	}
+	for k, d := range model {
+		if got, ok := st.Find(k); !ok || got != d {
+			t.Fatalf("Find(%s) = %s, %t; want %s, true", k, got, ok, d)
+		}
+	}
+	// Freed nodes are reused, so the slab never holds more than 200 nodes plus the nil node.
+	if len(st.nodes) > 201 {
+		t.Errorf("slab has grown to %d nodes", len(st.nodes))
+	}
+}
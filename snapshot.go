@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// `snapshotFile` is the format of a saved tree. It stores the complete tree structure,
+// so that a tree can be restored without inserting (and rebalancing) all values again.
+type snapshotFile struct {
+	Version int       `json:"version"`
+	Size    int       `json:"size"`
+	Root    *Snapshot `json:"root"`
+}
+
+const snapshotVersion = 1
+
+// `node` turns a snapshot back into a tree of nodes.
+func (s *Snapshot) node() *Node {
+	if s == nil {
+		return nil
+	}
+	return &Node{
+		Value:  s.Value,
+		Data:   s.Data,
+		height: s.Height,
+		Left:   s.Left.node(),
+		Right:  s.Right.node(),
+	}
+}
+
+// `Len` counts the nodes of the tree. This takes O(n) time: `Tree` keeps no count, because
+// `Root` is exported and can be replaced by anyone. Callers that need the size often,
+// like `RESPServer`, should keep their own count.
+func (t *Tree) Len() int {
+	n := 0
+	t.Traverse(t.Root, func(*Node) { n++ })
+	return n
+}
+
+// `WriteSnapshot` saves the tree to `w`. Use `ReadSnapshot` to restore it.
+func (t *Tree) WriteSnapshot(w io.Writer) error {
+	return json.NewEncoder(w).Encode(snapshotFile{
+		Version: snapshotVersion,
+		Size:    t.Len(),
+		Root:    t.Root.snapshot(),
+	})
+}
+
+// `ReadSnapshot` restores a tree that was saved by `WriteSnapshot`. As the file might
+// have been modified or damaged, `ReadSnapshot` validates the restored tree.
+func ReadSnapshot(r io.Reader) (*Tree, error) {
+	var f snapshotFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("cannot read snapshot: %w", err)
+	}
+	if f.Version != snapshotVersion {
+		return nil, fmt.Errorf("cannot read snapshot: unknown version %d", f.Version)
+	}
+	t := &Tree{Root: f.Root.node()}
+	if err := t.Validate(); err != nil {
+		return nil, fmt.Errorf("snapshot is damaged: %w", err)
+	}
+	if n := t.Len(); n != f.Size {
+		return nil, fmt.Errorf("snapshot is damaged: %d nodes, expected %d", n, f.Size)
+	}
+	return t, nil
+}
+
+// `saveSnapshot` and `loadSnapshot` call `WriteSnapshot` and `ReadSnapshot` for a file.
+func saveSnapshot(t *Tree, name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := t.WriteSnapshot(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func loadSnapshot(name string) (*Tree, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSnapshot(f)
+}
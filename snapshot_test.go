@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	tree := &Tree{}
+	for _, v := range []string{"d", "b", "g", "c", "e", "a", "h", "f", "i", "j", "l", "k"} {
+		tree.Insert(v, strings.ToUpper(v))
+	}
+	var buf bytes.Buffer
+	if err := tree.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalTrees(tree.Root, restored.Root) {
+		t.Errorf("restored tree differs from the original")
+	}
+	if restored.Len() != 12 {
+		t.Errorf("restored tree has %d nodes", restored.Len())
+	}
+
+	empty, err := ReadSnapshot(strings.NewReader(`{"version":1,"size":0}`))
+	if err != nil || empty.Root != nil {
+		t.Errorf("empty snapshot: %v, %v", empty, err)
+	}
+}
+
+// `equalTrees` compares the structure, values, data, and heights of two trees.
+func equalTrees(a, b *Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Value == b.Value && a.Data == b.Data && a.height == b.height &&
+		equalTrees(a.Left, b.Left) && equalTrees(a.Right, b.Right)
+}
+
+func TestReadSnapshotDamaged(t *testing.T) {
+	tests := []struct {
+		name, json string
+	}{
+		{"syntax", `{"version":1,"size":1,"root":{`},
+		{"version", `{"version":2,"size":0}`},
+		{"size", `{"version":1,"size":2,"root":{"value":"a","height":1}}`},
+		{"height", `{"version":1,"size":1,"root":{"value":"a","height":2}}`},
+		{"order", `{"version":1,"size":2,"root":{"value":"a","height":2,"left":{"value":"b","height":1}}}`},
+		{"balance", `{"version":1,"size":3,"root":{"value":"a","height":3,"right":{"value":"b","height":2,"right":{"value":"c","height":1}}}}`},
+	}
+	for _, tt := range tests {
+		if _, err := ReadSnapshot(strings.NewReader(tt.json)); err == nil {
+			t.Errorf("%s: damaged snapshot was accepted", tt.name)
+		}
+	}
+}
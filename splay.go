@@ -0,0 +1,149 @@
+package main
+
+import "os"
+
+// `SplayTree` is a self-adjusting binary search tree.
+//
+// A splay tree stores no balance information at all. Instead, every access moves the
+// accessed node to the root through a series of rotations ("splaying"). Frequently accessed
+// values therefore stay near the root, which makes splay trees a good fit for skewed access
+// patterns. A single operation can take O(n) steps, but any sequence of m operations takes
+// O(m log n) steps.
+//
+// Note that `Find` modifies the tree. Concurrent calls to `Find` need to be synchronized like writes.
+//
+// The zero value is an empty tree that is ready to use.
+type SplayTree struct {
+	Root *SplayNode
+}
+
+// `SplayNode` is a node of a `SplayTree`.
+type SplayNode struct {
+	Value string
+	Data  string
+	Left  *SplayNode
+	Right *SplayNode
+}
+
+// `rotateLeft` and `rotateRight` are the same rotations as in `Node`.
+func (n *SplayNode) rotateLeft() *SplayNode {
+	r := n.Right
+	n.Right = r.Left
+	r.Left = n
+	return r
+}
+
+func (n *SplayNode) rotateRight() *SplayNode {
+	l := n.Left
+	n.Left = l.Right
+	l.Right = n
+	return l
+}
+
+// `splay` moves the node with `value` to the top of the subtree at `n`. If there is no such node,
+// the last node on the search path moves to the top instead.
+//
+// Unlike simple "rotate to root", splaying rotates a node and its parent in pairs.
+// If node and parent are both left (or both right) children ("zig-zig"), the grandparent is rotated
+// first. This roughly halves the depth of all nodes on the search path.
+func (n *SplayNode) splay(value string) *SplayNode {
+	if n == nil || n.Value == value {
+		return n
+	}
+	if value < n.Value {
+		if n.Left == nil {
+			return n
+		}
+		switch {
+		case value < n.Left.Value:
+			// Zig-zig
+			n.Left.Left = n.Left.Left.splay(value)
+			n = n.rotateRight()
+		case value > n.Left.Value:
+			// Zig-zag
+			n.Left.Right = n.Left.Right.splay(value)
+			if n.Left.Right != nil {
+				n.Left = n.Left.rotateLeft()
+			}
+		}
+		if n.Left == nil {
+			return n
+		}
+		return n.rotateRight()
+	}
+	if n.Right == nil {
+		return n
+	}
+	switch {
+	case value > n.Right.Value:
+		n.Right.Right = n.Right.Right.splay(value)
+		n = n.rotateLeft()
+	case value < n.Right.Value:
+		n.Right.Left = n.Right.Left.splay(value)
+		if n.Right.Left != nil {
+			n.Right = n.Right.rotateRight()
+		}
+	}
+	if n.Right == nil {
+		return n
+	}
+	return n.rotateLeft()
+}
+
+// `Insert` inserts a value or updates the data of an existing one. The node ends up at the root.
+func (t *SplayTree) Insert(value, data string) {
+	if t.Root == nil {
+		t.Root = &SplayNode{Value: value, Data: data}
+		return
+	}
+	t.Root = t.Root.splay(value)
+	r := t.Root
+	if r.Value == value {
+		r.Data = data
+		return
+	}
+	// The root is now the neighbor of `value`. The new node takes its place and adopts it.
+	n := &SplayNode{Value: value, Data: data}
+	if value < r.Value {
+		n.Left, n.Right = r.Left, r
+		r.Left = nil
+	} else {
+		n.Left, n.Right = r, r.Right
+		r.Right = nil
+	}
+	t.Root = n
+}
+
+// `Find` returns the data stored for `value` and moves the node to the root.
+func (t *SplayTree) Find(value string) (string, bool) {
+	t.Root = t.Root.splay(value)
+	if t.Root == nil || t.Root.Value != value {
+		return "", false
+	}
+	return t.Root.Data, true
+}
+
+// `Walk` calls `f` for each value and its data in sort order.
+func (t *SplayTree) Walk(f func(value, data string)) {
+	var walk func(*SplayNode)
+	walk = func(n *SplayNode) {
+		if n == nil {
+			return
+		}
+		walk(n.Left)
+		f(n.Value, n.Data)
+		walk(n.Right)
+	}
+	walk(t.Root)
+}
+
+// `Dump` prints the tree like `Tree.Dump`, without balance and height.
+func (t *SplayTree) Dump() {
+	t.Root.Dump(0, "")
+}
+
+func (n *SplayNode) Dump(i int, lr string) {
+	dumpTree(os.Stdout, n, i, lr, func(n *SplayNode) (string, *SplayNode, *SplayNode) {
+		return n.Value, n.Left, n.Right
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func (n *SplayNode) isSorted(lo, hi *string) bool {
+	if n == nil {
+		return true
+	}
+	if (lo != nil && n.Value <= *lo) || (hi != nil && n.Value >= *hi) {
+		return false
+	}
+	return n.Left.isSorted(lo, &n.Value) && n.Right.isSorted(&n.Value, hi)
+}
+
+func TestSplayTree_Find(t *testing.T) {
+	st := &SplayTree{}
+	model := map[string]string{}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		k := strconv.Itoa(r.Intn(300))
+		if r.Intn(2) == 0 {
+			st.Insert(k, strconv.Itoa(i))
+			model[k] = strconv.Itoa(i)
+			if st.Root.Value != k {
+				t.Fatalf("Insert(%s) did not move the node to the root", k)
+			}
+		} else {
+			d, found := st.Find(k)
+			if md, mfound := model[k]; d != md || found != mfound {
+				t.Fatalf("Find(%s) = %s, %t; want %s, %t", k, d, found, md, mfound)
+			}
+			if found && st.Root.Value != k {
+				t.Fatalf("Find(%s) did not move the node to the root", k)
+			}
+		}
+		if !st.Root.isSorted(nil, nil) {
+			t.Fatalf("tree is not sorted after step %d", i)
+		}
+	}
+}
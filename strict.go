@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by `StrictTree.Insert` and `Tree.Update`.
+var (
+	ErrKeyExists   = errors.New("key exists")
+	ErrKeyNotFound = errors.New("key not found")
+)
+
+// `Update` replaces the data of an existing value. Unlike `Insert`, it does not add new values;
+// it returns an error wrapping `ErrKeyNotFound` instead.
+func (t *Tree) Update(value, data string) error {
+	if _, found := t.insertStep(value, data, replaceOnly); !found {
+		return fmt.Errorf("cannot update %s: %w", value, ErrKeyNotFound)
+	}
+	return nil
+}
+
+// `Upsert` is `Insert` with more information: it returns the previous data of `value`,
+// and whether it was replaced (true) or `value` was new (false).
+func (t *Tree) Upsert(value, data string) (old string, replaced bool) {
+	return t.insertStep(value, data, upsert)
+}
+
+// `GetOrInsert` returns the data of `value` and true if `value` exists. Otherwise,
+// it inserts `value` with `data` and returns `data` and false.
+func (t *Tree) GetOrInsert(value, data string) (actual string, loaded bool) {
+	if d, found := t.insertStep(value, data, addOnly); found {
+		return d, true
+	}
+	return data, false
+}
+
+// `StrictTree` is a `Tree` whose `Insert` refuses to overwrite existing values.
+// Use `Update` or `Upsert` to change the data of existing values.
+//
+// The zero value is an empty tree that is ready to use.
+type StrictTree struct {
+	Tree
+}
+
+// `Insert` adds a new value. If the value exists already, the data is left unchanged,
+// and `Insert` returns an error wrapping `ErrKeyExists`.
+func (t *StrictTree) Insert(value, data string) error {
+	if _, found := t.insertStep(value, data, addOnly); found {
+		return fmt.Errorf("cannot insert %s: %w", value, ErrKeyExists)
+	}
+	return nil
+}
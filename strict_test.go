@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStrictTree(t *testing.T) {
+	st := &StrictTree{}
+	if err := st.Insert("a", "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Insert("a", "alpha2"); !errors.Is(err, ErrKeyExists) {
+		t.Errorf("Insert of an existing value returned %v", err)
+	}
+	if d, _ := st.Find("a"); d != "alpha" {
+		t.Errorf("failed Insert has changed the data to %s", d)
+	}
+	if err := st.Update("b", "bravo"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Update of a missing value returned %v", err)
+	}
+	if _, found := st.Find("b"); found {
+		t.Errorf("failed Update has inserted a value")
+	}
+	if err := st.Update("a", "alpha3"); err != nil {
+		t.Errorf("Update of an existing value returned %v", err)
+	}
+	if d, _ := st.Find("a"); d != "alpha3" {
+		t.Errorf("Update has not changed the data")
+	}
+}
+
+func TestTree_Upsert(t *testing.T) {
+	tree := &Tree{}
+	if old, replaced := tree.Upsert("a", "alpha"); replaced || old != "" {
+		t.Errorf("Upsert of a new value returned %s, %t", old, replaced)
+	}
+	if old, replaced := tree.Upsert("a", "alpha2"); !replaced || old != "alpha" {
+		t.Errorf("Upsert of an existing value returned %s, %t", old, replaced)
+	}
+	if d, loaded := tree.GetOrInsert("a", "alpha3"); !loaded || d != "alpha2" {
+		t.Errorf("GetOrInsert of an existing value returned %s, %t", d, loaded)
+	}
+	if d, loaded := tree.GetOrInsert("b", "bravo"); loaded || d != "bravo" {
+		t.Errorf("GetOrInsert of a new value returned %s, %t", d, loaded)
+	}
+	if d, _ := tree.Find("b"); d != "bravo" {
+		t.Errorf("GetOrInsert has not inserted the value")
+	}
+}
+
+// Operations that leave the tree unchanged must not show up in a recording.
+func TestTree_StrictRecorded(t *testing.T) {
+	rec := &Recorder{}
+	st := &StrictTree{Tree{Recorder: rec}}
+	st.Insert("a", "alpha")
+	st.Insert("a", "alpha2")
+	st.Update("b", "bravo")
+	st.GetOrInsert("a", "alpha3")
+	st.Update("a", "alpha4")
+	if len(rec.Steps) != 2 || rec.Steps[1].Data != "alpha4" {
+		t.Errorf("expected two recorded steps, got %+v", rec.Steps)
+	}
+}
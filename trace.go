@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// `Snapshot` is a deep copy of a (sub-)tree, taken at a particular point in time.
+// Unlike `Node`, all fields are exported, so that a snapshot can be serialized to JSON.
+type Snapshot struct {
+	Value  string    `json:"value"`
+	Data   string    `json:"data"`
+	Height int       `json:"height"`
+	Left   *Snapshot `json:"left,omitempty"`
+	Right  *Snapshot `json:"right,omitempty"`
+}
+
+// `snapshot` copies the subtree at node `n`.
+func (n *Node) snapshot() *Snapshot {
+	if n == nil {
+		return nil
+	}
+	return &Snapshot{
+		Value:  n.Value,
+		Data:   n.Data,
+		Height: n.height,
+		Left:   n.Left.snapshot(),
+		Right:  n.Right.snapshot(),
+	}
+}
+
+// `height` and `Bal` mirror the methods of the same name on `Node`.
+func (s *Snapshot) height() int {
+	if s == nil {
+		return 0
+	}
+	return s.Height
+}
+
+func (s *Snapshot) Bal() int {
+	return s.Right.height() - s.Left.height()
+}
+
+// `Dump` writes the snapshot to `w` in the same format as `Node.Dump`.
+func (s *Snapshot) Dump(w io.Writer, i int, lr string) {
+	dumpTree(w, s, i, lr, func(s *Snapshot) (string, *Snapshot, *Snapshot) {
+		return fmt.Sprintf("%s[%d,%d]", s.Value, s.Bal(), s.Height), s.Left, s.Right
+	})
+}
+
+// `Rotation` describes a single call to one of the rotation methods.
+// `Depth` is 0 for the outermost rotation of a rebalancing step and 1 for
+// the single rotations that a double rotation is made of.
+type Rotation struct {
+	Kind   string    `json:"kind"`
+	Node   string    `json:"node"`
+	Depth  int       `json:"depth"`
+	Before *Snapshot `json:"before"`
+	After  *Snapshot `json:"after"`
+}
+
+// `Step` is one call to `Tree.Insert` or `Tree.Delete`, the rotations it triggered in call order,
+// and the complete tree after the operation.
+type Step struct {
+	// `Op` is "insert" or "delete".
+	Op        string     `json:"op"`
+	Value     string     `json:"value"`
+	Data      string     `json:"data"`
+	Rotations []Rotation `json:"rotations"`
+	Tree      *Snapshot  `json:"tree"`
+}
+
+// `Recorder` collects the history of a tree. Set `Tree.Recorder` to start recording.
+type Recorder struct {
+	Steps []Step `json:"steps"`
+	// `depth` tracks nested rotation calls while an insert is in progress.
+	depth int
+}
+
+// `begin` starts a new step. Like all unexported `Recorder` methods, it does nothing if `r` is nil.
+func (r *Recorder) begin(op, value, data string) {
+	if r == nil {
+		return
+	}
+	r.Steps = append(r.Steps, Step{Op: op, Value: value, Data: data, Rotations: []Rotation{}})
+	r.depth = 0
+}
+
+// `end` finishes the current step by taking a snapshot of the whole tree.
+func (r *Recorder) end(root *Node) {
+	if r == nil || len(r.Steps) == 0 {
+		return
+	}
+	r.Steps[len(r.Steps)-1].Tree = root.snapshot()
+}
+
+// `discard` drops the current step, for operations that turn out to leave the tree unchanged.
+func (r *Recorder) discard() {
+	if r == nil || len(r.Steps) == 0 {
+		return
+	}
+	r.Steps = r.Steps[:len(r.Steps)-1]
+}
+
+// `rotation` records the subtree at `n` before a rotation. The returned function
+// must be called with the new top node of the subtree when the rotation is done.
+// Rotations outside of `begin` and `end` (that is, through `Node.Insert`) are not recorded.
+func (r *Recorder) rotation(kind string, n *Node) func(*Node) {
+	if r == nil || len(r.Steps) == 0 {
+		return func(*Node) {}
+	}
+	step := &r.Steps[len(r.Steps)-1]
+	step.Rotations = append(step.Rotations, Rotation{
+		Kind:   kind,
+		Node:   n.Value,
+		Depth:  r.depth,
+		Before: n.snapshot(),
+	})
+	// Remember the index, not a pointer; nested rotations may grow the slice.
+	i := len(step.Rotations) - 1
+	r.depth++
+	return func(top *Node) {
+		r.depth--
+		step.Rotations[i].After = top.snapshot()
+	}
+}
+
+// `WriteJSON` serializes the recorded history.
+func (r *Recorder) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// `ReadRecording` reads a history that was written by `WriteJSON`.
+func ReadRecording(rd io.Reader) (*Recorder, error) {
+	r := &Recorder{}
+	if err := json.NewDecoder(rd).Decode(r); err != nil {
+		return nil, fmt.Errorf("cannot read recording: %w", err)
+	}
+	return r, nil
+}
+
+// `Frame` is a single position in a replay. A step with n rotations
+// consists of n frames showing one rotation each, followed by a frame
+// that shows the complete tree after the insert (with `Rotation` == nil).
+type Frame struct {
+	Step     int
+	Op       string
+	Value    string
+	Data     string
+	Rotation *Rotation
+	Tree     *Snapshot
+}
+
+// `Replayer` steps forward and backward through a recorded history.
+type Replayer struct {
+	frames []Frame
+	pos    int
+}
+
+// `NewReplayer` creates a replayer that is positioned at the first frame.
+func NewReplayer(r *Recorder) *Replayer {
+	p := &Replayer{}
+	for i := range r.Steps {
+		s := &r.Steps[i]
+		for j := range s.Rotations {
+			p.frames = append(p.frames, Frame{Step: i, Op: s.Op, Value: s.Value, Data: s.Data, Rotation: &s.Rotations[j]})
+		}
+		p.frames = append(p.frames, Frame{Step: i, Op: s.Op, Value: s.Value, Data: s.Data, Tree: s.Tree})
+	}
+	return p
+}
+
+// `Len` returns the number of frames.
+func (p *Replayer) Len() int {
+	return len(p.frames)
+}
+
+// `Pos` returns the index of the current frame.
+func (p *Replayer) Pos() int {
+	return p.pos
+}
+
+// `Frame` returns the current frame. It returns false if there are no frames at all.
+func (p *Replayer) Frame() (Frame, bool) {
+	if len(p.frames) == 0 {
+		return Frame{}, false
+	}
+	return p.frames[p.pos], true
+}
+
+// `Next` moves one frame forward. It returns false if the replayer is already at the last frame.
+func (p *Replayer) Next() bool {
+	if p.pos >= len(p.frames)-1 {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+// `Prev` moves one frame back. It returns false if the replayer is already at the first frame.
+func (p *Replayer) Prev() bool {
+	if p.pos == 0 {
+		return false
+	}
+	p.pos--
+	return true
+}
+
+// `Seek` jumps to frame `i`.
+func (p *Replayer) Seek(i int) bool {
+	if i < 0 || i >= len(p.frames) {
+		return false
+	}
+	p.pos = i
+	return true
+}
+
+// `Print` writes the current frame in a human-readable form.
+func (p *Replayer) Print(w io.Writer) {
+	f, ok := p.Frame()
+	if !ok {
+		return
+	}
+	if f.Op == "delete" {
+		fmt.Fprintf(w, "Delete %s\n", f.Value)
+	} else {
+		fmt.Fprintf(w, "Insert %s: %s\n", f.Value, f.Data)
+	}
+	if f.Rotation == nil {
+		f.Tree.Dump(w, 0, "")
+		return
+	}
+	fmt.Fprintf(w, "%s %s\nBefore:\n", f.Rotation.Kind, f.Rotation.Node)
+	f.Rotation.Before.Dump(w, 0, "")
+	fmt.Fprintln(w, "After:")
+	f.Rotation.After.Dump(w, 0, "")
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := &Recorder{}
+	tree := &Tree{Recorder: rec}
+	// "a", "b", "c" causes a single left rotation at "a",
+	// "f", "d" then causes a double rotation at "c".
+	for _, v := range []string{"a", "b", "c", "f", "d"} {
+		tree.Insert(v, v)
+	}
+	if len(rec.Steps) != 5 {
+		t.Fatalf("expected 5 steps, got %d", len(rec.Steps))
+	}
+
+	rots := rec.Steps[2].Rotations
+	if len(rots) != 1 || rots[0].Kind != "rotateLeft" || rots[0].Node != "a" {
+		t.Fatalf("step 3: expected rotateLeft at a, got %+v", rots)
+	}
+	if rots[0].Before.Value != "a" || rots[0].After.Value != "b" {
+		t.Errorf("step 3: expected subtree root to change from a to b, got %s to %s", rots[0].Before.Value, rots[0].After.Value)
+	}
+
+	var kinds []string
+	for _, r := range rec.Steps[4].Rotations {
+		kinds = append(kinds, r.Kind+" "+r.Node)
+	}
+	want := []string{"rotateRightLeft c", "rotateRight f", "rotateLeft c"}
+	if len(kinds) != len(want) {
+		t.Fatalf("step 5: expected %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("step 5: expected %v, got %v", want, kinds)
+		}
+	}
+	if rec.Steps[4].Rotations[0].Depth != 0 || rec.Steps[4].Rotations[1].Depth != 1 {
+		t.Errorf("step 5: wrong rotation depths")
+	}
+	if rec.Steps[4].Tree.Value != tree.Root.Value {
+		t.Errorf("final snapshot has root %s, tree has root %s", rec.Steps[4].Tree.Value, tree.Root.Value)
+	}
+
+	var buf bytes.Buffer
+	if err := rec.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rec2, err := ReadRecording(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec2.Steps) != len(rec.Steps) || rec2.Steps[4].Rotations[2].After.Value != "d" {
+		t.Errorf("recording did not survive the JSON round trip")
+	}
+
+	p := NewReplayer(rec2)
+	// 5 final frames plus 4 rotation frames
+	if p.Len() != 9 {
+		t.Fatalf("expected 9 frames, got %d", p.Len())
+	}
+	for p.Next() {
+	}
+	if p.Pos() != 8 || !p.Prev() || p.Pos() != 7 {
+		t.Errorf("cannot step back from the last frame")
+	}
+	f, _ := p.Frame()
+	if f.Rotation == nil || f.Rotation.Kind != "rotateLeft" {
+		t.Errorf("expected frame 7 to show rotateLeft, got %+v", f)
+	}
+	for p.Prev() {
+	}
+	if p.Pos() != 0 {
+		t.Errorf("cannot step back to the first frame")
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+)
+
+// `Treap` is a binary search tree that is balanced by random priorities.
+//
+// Each node gets a random priority when it is inserted. Besides being sorted by value,
+// the tree is also a heap with respect to priority: no node has a higher priority than its parent.
+// Inserting works like in an unbalanced binary search tree, but then the new node
+// is rotated upwards until its parent has a higher priority. On average, the result
+// is as balanced as a tree built from randomly ordered values.
+//
+// Treaps can be split and merged easily, which is why `Split` and `Merge` are included.
+//
+// The zero value is an empty treap whose priorities are generated with seed 1.
+// Use `NewTreap` to choose a different seed.
+type Treap struct {
+	Root *TreapNode
+	rand *rand.Rand
+}
+
+// `TreapNode` is a node of a `Treap`.
+type TreapNode struct {
+	Value    string
+	Data     string
+	Left     *TreapNode
+	Right    *TreapNode
+	priority int64
+}
+
+// `NewTreap` creates an empty treap. The same seed creates the same tree shapes
+// for the same sequence of operations, which is useful for tests.
+func NewTreap(seed int64) *Treap {
+	return &Treap{rand: rand.New(rand.NewSource(seed))}
+}
+
+func (t *Treap) nextPriority() int64 {
+	if t.rand == nil {
+		t.rand = rand.New(rand.NewSource(1))
+	}
+	return t.rand.Int63()
+}
+
+// `rotateLeft` and `rotateRight` are the same rotations as in `Node`, minus the height bookkeeping.
+func (n *TreapNode) rotateLeft() *TreapNode {
+	r := n.Right
+	n.Right = r.Left
+	r.Left = n
+	return r
+}
+
+func (n *TreapNode) rotateRight() *TreapNode {
+	l := n.Left
+	n.Left = l.Right
+	l.Right = n
+	return l
+}
+
+// `insert` inserts the new node `nn` into the subtree at `n`. On the way back up,
+// it rotates `nn` above every node with a lower priority.
+func (n *TreapNode) insert(nn *TreapNode) *TreapNode {
+	if n == nil {
+		return nn
+	}
+	switch {
+	case nn.Value == n.Value:
+		n.Data = nn.Data
+	case nn.Value < n.Value:
+		n.Left = n.Left.insert(nn)
+		if n.Left.priority > n.priority {
+			n = n.rotateRight()
+		}
+	default:
+		n.Right = n.Right.insert(nn)
+		if n.Right.priority > n.priority {
+			n = n.rotateLeft()
+		}
+	}
+	return n
+}
+
+// `Insert` inserts a value or updates the data of an existing one.
+func (t *Treap) Insert(value, data string) {
+	t.Root = t.Root.insert(&TreapNode{Value: value, Data: data, priority: t.nextPriority()})
+}
+
+// `Find` returns the data stored for `value`.
+func (t *Treap) Find(value string) (string, bool) {
+	n := t.Root
+	for n != nil {
+		switch {
+		case value == n.Value:
+			return n.Data, true
+		case value < n.Value:
+			n = n.Left
+		default:
+			n = n.Right
+		}
+	}
+	return "", false
+}
+
+// `split` splits the subtree at `n` into the values smaller than `value` and the values
+// larger than or equal to `value`.
+func (n *TreapNode) split(value string) (*TreapNode, *TreapNode) {
+	if n == nil {
+		return nil, nil
+	}
+	if n.Value < value {
+		l, r := n.Right.split(value)
+		n.Right = l
+		return n, r
+	}
+	l, r := n.Left.split(value)
+	n.Left = r
+	return l, n
+}
+
+// `merge` joins two subtrees. All values in `l` must be smaller than all values in `r`.
+func merge(l, r *TreapNode) *TreapNode {
+	switch {
+	case l == nil:
+		return r
+	case r == nil:
+		return l
+	case l.priority > r.priority:
+		l.Right = merge(l.Right, r)
+		return l
+	default:
+		r.Left = merge(l, r.Left)
+		return r
+	}
+}
+
+// `Split` moves all values that are larger than or equal to `value` into a new treap
+// and returns it. `t` keeps the smaller values. Both treaps share the random source.
+func (t *Treap) Split(value string) *Treap {
+	l, r := t.Root.split(value)
+	t.Root = l
+	return &Treap{Root: r, rand: t.rand}
+}
+
+// `Merge` moves all values from `u` into `t` and leaves `u` empty.
+// All values in `t` must be smaller than all values in `u`; otherwise `Merge` returns an error
+// and leaves both treaps unchanged.
+func (t *Treap) Merge(u *Treap) error {
+	if t.Root != nil && u.Root != nil {
+		last, first := t.Root, u.Root
+		for last.Right != nil {
+			last = last.Right
+		}
+		for first.Left != nil {
+			first = first.Left
+		}
+		if last.Value >= first.Value {
+			return fmt.Errorf("cannot merge: %s is not smaller than %s", last.Value, first.Value)
+		}
+	}
+	t.Root = merge(t.Root, u.Root)
+	u.Root = nil
+	return nil
+}
+
+// `Delete` removes `value` from the treap. It returns false if there was no such value.
+func (t *Treap) Delete(value string) bool {
+	// Cut out the node with `value` by splitting around it, then merge the remaining parts.
+	l, r := t.Root.split(value)
+	m, r := r.split(value + "\x00")
+	t.Root = merge(l, r)
+	return m != nil
+}
+
+// `Walk` calls `f` for each value and its data in sort order.
+func (t *Treap) Walk(f func(value, data string)) {
+	var walk func(*TreapNode)
+	walk = func(n *TreapNode) {
+		if n == nil {
+			return
+		}
+		walk(n.Left)
+		f(n.Value, n.Data)
+		walk(n.Right)
+	}
+	walk(t.Root)
+}
+
+// `Dump` prints the treap like `Tree.Dump`, but with the priorities instead of balance and height.
+func (t *Treap) Dump() {
+	t.Root.Dump(0, "")
+}
+
+func (n *TreapNode) Dump(i int, lr string) {
+	dumpTree(os.Stdout, n, i, lr, func(n *TreapNode) (string, *TreapNode, *TreapNode) {
+		return fmt.Sprintf("%s[%d]", n.Value, n.priority), n.Left, n.Right
+	})
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+// checkHeap verifies that the subtree at n is sorted and that no node
+// has a higher priority than its parent.
+func (n *TreapNode) checkHeap(tb testing.TB, lo, hi *string) {
+	if n == nil {
+		return
+	}
+	if (lo != nil && n.Value <= *lo) || (hi != nil && n.Value >= *hi) {
+		tb.Fatalf("node %s is out of order", n.Value)
+	}
+	for _, c := range []*TreapNode{n.Left, n.Right} {
+		if c != nil && c.priority > n.priority {
+			tb.Fatalf("node %s has a higher priority than its parent %s", c.Value, n.Value)
+		}
+	}
+	n.Left.checkHeap(tb, lo, &n.Value)
+	n.Right.checkHeap(tb, &n.Value, hi)
+}
+
+func TestTreap_heap(t *testing.T) {
+	for _, tree := range trees {
+		t.Run(tree.name, func(t *testing.T) {
+			tp := NewTreap(1)
+			for i := range tree.value {
+				tp.Insert(tree.value[i], tree.data[i])
+				tp.Root.checkHeap(t, nil, nil)
+			}
+		})
+	}
+}
+
+func TestTreap_deterministic(t *testing.T) {
+	a, b := NewTreap(7), NewTreap(7)
+	for i := 0; i < 100; i++ {
+		a.Insert(strconv.Itoa(i), "")
+		b.Insert(strconv.Itoa(i), "")
+	}
+	var same func(m, n *TreapNode) bool
+	same = func(m, n *TreapNode) bool {
+		if m == nil || n == nil {
+			return m == n
+		}
+		return m.Value == n.Value && m.priority == n.priority && same(m.Left, n.Left) && same(m.Right, n.Right)
+	}
+	if !same(a.Root, b.Root) {
+		t.Errorf("treaps with the same seed have different shapes")
+	}
+}
+
+func TestTreap_SplitMerge(t *testing.T) {
+	tp := NewTreap(1)
+	for i := 10; i < 60; i++ {
+		tp.Insert(strconv.Itoa(i), strconv.Itoa(i))
+	}
+	right := tp.Split("35")
+	tp.Root.checkHeap(t, nil, nil)
+	right.Root.checkHeap(t, nil, nil)
+	if _, ok := tp.Find("35"); ok {
+		t.Errorf("35 is still in the left part")
+	}
+	if _, ok := right.Find("35"); !ok {
+		t.Errorf("35 is missing from the right part")
+	}
+	if err := right.Merge(tp); err == nil {
+		t.Errorf("merging in the wrong order succeeded")
+	}
+	if err := tp.Merge(right); err != nil {
+		t.Fatal(err)
+	}
+	tp.Root.checkHeap(t, nil, nil)
+	if !tp.Delete("35") || tp.Delete("35") {
+		t.Errorf("Delete(35) does not work")
+	}
+	n := 0
+	tp.Walk(func(v, d string) { n++ })
+	if n != 49 || right.Root != nil {
+		t.Errorf("expected 49 values after merge and delete, got %d", n)
+	}
+	tp.Root.checkHeap(t, nil, nil)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// `ViolationKind` tells which invariant a node violates.
+type ViolationKind int
+
+const (
+	// The height stored in the node differs from the actual height of its subtree.
+	WrongHeight ViolationKind = iota
+	// The heights of the node's subtrees differ by more than the tree's `Policy` allows.
+	Unbalanced
+	// The node's value is not in sort order relative to its ancestors.
+	Unordered
+	// The node's value also exists in one of its ancestors.
+	Duplicate
+	// The aggregate stored in the node does not match the data in its subtree.
+	WrongAggregate
+)
+
+func (k ViolationKind) String() string {
+	switch k {
+	case WrongHeight:
+		return "wrong height"
+	case Unbalanced:
+		return "unbalanced"
+	case Unordered:
+		return "unordered"
+	case Duplicate:
+		return "duplicate"
+	case WrongAggregate:
+		return "wrong aggregate"
+	}
+	return fmt.Sprintf("ViolationKind(%d)", int(k))
+}
+
+// `Violation` describes a single node that violates an invariant.
+type Violation struct {
+	Kind    ViolationKind
+	Value   string
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("node %s: %s: %s", v.Value, v.Kind, v.Message)
+}
+
+// `ValidationError` lists all violations found by `Validate`.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	s := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		s[i] = v.String()
+	}
+	return fmt.Sprintf("tree is invalid (%d violations): %s", len(e.Violations), strings.Join(s, "; "))
+}
+
+// `Validate` checks the AVL invariants of the tree:
+//
+//   - Each node's stored height matches the actual height of its subtree.
+//   - The heights of the two child subtrees of any node differ by at most one,
+//     or by as much as the tree's `Policy` allows.
+//   - If the tree has an `Aggregate`, each node's aggregate matches the data in its subtree.
+//   - All values in a node's left subtree are smaller, and all values in its right subtree
+//     are larger than the node's value. In particular, no value occurs twice.
+//
+// It returns nil if the tree is valid, or a `*ValidationError` that lists each violating node.
+func (t *Tree) Validate() error {
+	var vs []Violation
+	t.Root.validate(t.Policy().maxBal(), nil, nil, &vs)
+	if t.aggregate != nil {
+		t.Root.validateAggregates(t.aggregate, &vs)
+	}
+	if len(vs) > 0 {
+		return &ValidationError{Violations: vs}
+	}
+	return nil
+}
+
+// `validate` checks the subtree at `n`, appends all violations to `vs`, and returns
+// the actual height of the subtree. Computing the height bottom-up avoids calling
+// `recHeight`-style functions repeatedly for each node.
+//
+// Comparing a node to its direct children is not sufficient to detect
+// a node that is on the wrong side of its grandparent. Therefore, `lo` and `hi` carry the
+// bounds for the subtree down the recursion: every value must be larger than `*lo` and smaller than `*hi`.
+// A nil bound means there is no bound on that side.
+//
+// `k` is the largest allowed height difference between the subtrees of a node. If `k` is negative,
+// the balance is not checked.
+func (n *Node) validate(k int, lo, hi *string, vs *[]Violation) int {
+	if n == nil {
+		return 0
+	}
+	switch {
+	case lo != nil && n.Value == *lo, hi != nil && n.Value == *hi:
+		*vs = append(*vs, Violation{Duplicate, n.Value, "value occurs more than once"})
+	case lo != nil && n.Value < *lo:
+		*vs = append(*vs, Violation{Unordered, n.Value, fmt.Sprintf("is in the right subtree of the larger value %s", *lo)})
+	case hi != nil && n.Value > *hi:
+		*vs = append(*vs, Violation{Unordered, n.Value, fmt.Sprintf("is in the left subtree of the smaller value %s", *hi)})
+	}
+	lh := n.Left.validate(k, lo, &n.Value, vs)
+	rh := n.Right.validate(k, &n.Value, hi, vs)
+	h := max(lh, rh) + 1
+
+	if n.height != h {
+		*vs = append(*vs, Violation{WrongHeight, n.Value, fmt.Sprintf("stored height %d, actual height %d", n.height, h)})
+	}
+	if k >= 0 && (rh-lh < -k || rh-lh > k) {
+		*vs = append(*vs, Violation{Unbalanced, n.Value, fmt.Sprintf("right height %d, left height %d", rh, lh)})
+	}
+	return h
+}
+
+// `validateAggregates` checks the stored aggregates of the subtree at `n` and returns the
+// actual aggregate of the subtree.
+func (n *Node) validateAggregates(a *Aggregate, vs *[]Violation) string {
+	if n == nil {
+		return a.Identity
+	}
+	l := n.Left.validateAggregates(a, vs)
+	r := n.Right.validateAggregates(a, vs)
+	agg := a.Combine(a.Combine(l, n.Data), r)
+	if n.agg != agg {
+		*vs = append(*vs, Violation{WrongAggregate, n.Value, fmt.Sprintf("stored aggregate %q, actual aggregate %q", n.agg, agg)})
+	}
+	return agg
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTree_Validate(t *testing.T) {
+	for _, tree := range trees {
+		t.Run(tree.name, func(t *testing.T) {
+			if err := newTree(tree).Validate(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestTree_ValidateCorrupt(t *testing.T) {
+	for _, c := range corruptTrees {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.tree.Validate()
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a *ValidationError, got %v", err)
+			}
+			for _, v := range verr.Violations {
+				if v.Kind == c.kind && v.Value == c.value {
+					return
+				}
+			}
+			t.Errorf("expected %s violation at node %s, got %v", c.kind, c.value, verr.Violations)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"html/template"
+	"io"
+	"os"
+	"strings"
+)
+
+// `WriteHTML` writes a self-contained HTML page that animates the recorded
+// inserts, deletes, and rotations, similar to the diagrams in the article.
+// The page needs no network access; all scripts and styles are embedded.
+func (r *Recorder) WriteHTML(w io.Writer, title string) error {
+	return htmlTemplate.Execute(w, struct {
+		Title string
+		Steps []Step
+	}{title, r.Steps})
+}
+
+// `Visualize` inserts `values` (with the corresponding `data`) into a new tree and writes
+// the HTML animation of all inserts to `w`.
+// If `data` is shorter than `values`, the remaining values are used as their own data.
+func Visualize(w io.Writer, title string, values, data []string) error {
+	rec := &Recorder{}
+	tree := &Tree{Recorder: rec}
+	for i, v := range values {
+		d := v
+		if i < len(data) {
+			d = data[i]
+		}
+		tree.Insert(v, d)
+	}
+	return rec.WriteHTML(w, title)
+}
+
+// In a <script> context, html/template encodes `.Steps` as JSON.
+var htmlTemplate = template.Must(template.New("tree").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+#caption { font-size: 1.2em; margin: 0.5em 0; min-height: 1.5em; }
+svg { border: 1px solid #ccc; }
+circle { fill: #fff; stroke: #333; stroke-width: 2; }
+circle.hl { fill: #fc6; }
+line { stroke: #333; stroke-width: 2; }
+text { font-size: 14px; text-anchor: middle; dominant-baseline: central; }
+text.bal { font-size: 10px; fill: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div>
+<button id="first">|&lt;</button>
+<button id="prev">&lt;</button>
+<button id="play">Play</button>
+<button id="next">&gt;</button>
+<button id="last">&gt;|</button>
+<span id="counter"></span>
+</div>
+<div id="caption"></div>
+<svg id="tree" width="800" height="400"></svg>
+<script>
+var steps = {{.Steps}};
+var doubles = {"rotateLeftRight": true, "rotateRightLeft": true};
+
+// Turn the steps into a flat list of frames. Double rotations are shown
+// through the two single rotations they consist of.
+var frames = [];
+(steps || []).forEach(function(s) {
+	var ins = s.op === "delete" ? "Delete " + s.value : "Insert " + s.value + ": " + s.data;
+	(s.rotations || []).forEach(function(r) {
+		if (doubles[r.kind]) {
+			return;
+		}
+		frames.push({caption: ins + " - before " + r.kind + " at " + r.node, tree: r.before, hl: r.node});
+		frames.push({caption: ins + " - after " + r.kind + " at " + r.node, tree: r.after, hl: r.node});
+	});
+	frames.push({caption: ins + " - done", tree: s.tree, hl: s.value});
+});
+
+function height(n) { return n ? n.height : 0; }
+
+// Nodes are placed by their in-order position (x) and their depth (y).
+function layout(root) {
+	var pos = {}, edges = [], i = 0;
+	function walk(n, d, parent) {
+		if (!n) { return; }
+		walk(n.left, d + 1, n.value);
+		pos[n.value] = {x: i++, y: d, bal: height(n.right) - height(n.left)};
+		if (parent !== null) { edges.push([parent, n.value]); }
+		walk(n.right, d + 1, n.value);
+	}
+	walk(root, 0, null);
+	return {pos: pos, edges: edges, width: i};
+}
+
+var svg = document.getElementById("tree");
+var ns = "http://www.w3.org/2000/svg";
+var cur = 0, shown = null, timer = null;
+
+function el(name, attrs, text) {
+	var e = document.createElementNS(ns, name);
+	for (var k in attrs) { e.setAttribute(k, attrs[k]); }
+	if (text !== undefined) { e.textContent = text; }
+	svg.appendChild(e);
+	return e;
+}
+
+function coords(l, p) {
+	var w = svg.clientWidth || 800;
+	var dx = w / (l.width + 1);
+	return {x: (p.x + 1) * dx, y: 40 + p.y * 60};
+}
+
+function draw(l, from, t, hl) {
+	while (svg.firstChild) { svg.removeChild(svg.firstChild); }
+	function at(v) {
+		var c = coords(l, l.pos[v]);
+		if (from && from.pos[v] && t < 1) {
+			var o = coords(from, from.pos[v]);
+			c = {x: o.x + (c.x - o.x) * t, y: o.y + (c.y - o.y) * t};
+		}
+		return c;
+	}
+	l.edges.forEach(function(e) {
+		var a = at(e[0]), b = at(e[1]);
+		el("line", {x1: a.x, y1: a.y, x2: b.x, y2: b.y});
+	});
+	Object.keys(l.pos).forEach(function(v) {
+		var c = at(v);
+		el("circle", {cx: c.x, cy: c.y, r: 16, "class": v === hl ? "hl" : ""});
+		el("text", {x: c.x, y: c.y}, v);
+		el("text", {x: c.x, y: c.y - 24, "class": "bal"}, l.pos[v].bal);
+	});
+}
+
+// Move every node that is visible in both frames from its old to its new position.
+function show(i) {
+	if (frames.length === 0) { return; }
+	cur = Math.max(0, Math.min(frames.length - 1, i));
+	var f = frames[cur], l = layout(f.tree), from = shown, start = null;
+	document.getElementById("caption").textContent = f.caption;
+	document.getElementById("counter").textContent = (cur + 1) + " / " + frames.length;
+	shown = l;
+	function tick(ts) {
+		if (start === null) { start = ts; }
+		var t = Math.min(1, (ts - start) / 500);
+		draw(l, from, t, f.hl);
+		if (t < 1 && shown === l) { requestAnimationFrame(tick); }
+	}
+	requestAnimationFrame(tick);
+}
+
+function stop() {
+	clearInterval(timer);
+	timer = null;
+	document.getElementById("play").textContent = "Play";
+}
+
+document.getElementById("first").onclick = function() { stop(); show(0); };
+document.getElementById("prev").onclick = function() { stop(); show(cur - 1); };
+document.getElementById("next").onclick = function() { stop(); show(cur + 1); };
+document.getElementById("last").onclick = function() { stop(); show(frames.length - 1); };
+document.getElementById("play").onclick = function() {
+	if (timer) { stop(); return; }
+	this.textContent = "Pause";
+	timer = setInterval(function() {
+		if (cur >= frames.length - 1) { stop(); return; }
+		show(cur + 1);
+	}, 1200);
+};
+show(0);
+</script>
+</body>
+</html>
+`))
+
+// `writeHTMLFile` calls `Visualize` for the given file name.
+func writeHTMLFile(name string, values, data []string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := Visualize(f, "Balanced tree: "+strings.Join(values, " "), values, data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVisualize(t *testing.T) {
+	var buf bytes.Buffer
+	err := Visualize(&buf, "a <b> c", []string{"c", "a", "b"}, []string{"charlie"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	for _, want := range []string{
+		"<title>a &lt;b&gt; c</title>",
+		`"kind":"rotateLeftRight"`,
+		`"data":"charlie"`,
+		`"data":"a"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page does not contain %s", want)
+		}
+	}
+	// The page must be self-contained.
+	if strings.Contains(page, "src=") || strings.Contains(page, "href=") {
+		t.Errorf("page references external resources")
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// `WBTree` is a weight-balanced tree.
+//
+// Where an AVL tree balances the heights of subtrees, a weight-balanced tree balances their sizes:
+// neither subtree may be more than `wbDelta` times as heavy as the other, where the weight of a subtree
+// is its size plus one. (Remember the picture of the tree with no weight balance from the article?
+// A `WBTree` would not accept it.)
+//
+// Each node stores the size of its subtree, so the tree supports rank queries natively:
+// `Rank` returns the position of a value, `Select` returns the value at a position.
+//
+// The zero value is an empty tree that is ready to use.
+type WBTree struct {
+	Root *WBNode
+}
+
+// `WBNode` is a node of a `WBTree`.
+type WBNode struct {
+	Value string
+	Data  string
+	Left  *WBNode
+	Right *WBNode
+	size  int
+}
+
+// The balance parameters. (3, 2) is the only integer pair for which
+// insert and delete are proven to keep the tree in balance.
+const (
+	wbDelta = 3
+	wbGamma = 2
+)
+
+// `Size` returns the number of nodes in the subtree at `n`. Like `Node.Height`, it works for nil nodes.
+func (n *WBNode) Size() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *WBNode) weight() int {
+	return n.Size() + 1
+}
+
+func (n *WBNode) fixSize() {
+	n.size = n.Left.Size() + n.Right.Size() + 1
+}
+
+// `rotateLeft` and `rotateRight` are the same rotations as in `Node`,
+// but they update sizes instead of heights.
+func (n *WBNode) rotateLeft() *WBNode {
+	r := n.Right
+	n.Right = r.Left
+	r.Left = n
+	n.fixSize()
+	r.fixSize()
+	return r
+}
+
+func (n *WBNode) rotateRight() *WBNode {
+	l := n.Left
+	n.Left = l.Right
+	l.Right = n
+	n.fixSize()
+	l.fixSize()
+	return l
+}
+
+// `rebalance` restores the weight balance at `n`. If the inner grandchild is too heavy,
+// a single rotation would not help, and a double rotation is required, just like in an AVL tree.
+func (n *WBNode) rebalance() *WBNode {
+	n.fixSize()
+	switch {
+	case n.Left.weight()*wbDelta < n.Right.weight():
+		if n.Right.Left.weight() >= wbGamma*n.Right.Right.weight() {
+			n.Right = n.Right.rotateRight()
+		}
+		return n.rotateLeft()
+	case n.Right.weight()*wbDelta < n.Left.weight():
+		if n.Left.Right.weight() >= wbGamma*n.Left.Left.weight() {
+			n.Left = n.Left.rotateLeft()
+		}
+		return n.rotateRight()
+	}
+	return n
+}
+
+func (n *WBNode) insert(value, data string) *WBNode {
+	if n == nil {
+		return &WBNode{Value: value, Data: data, size: 1}
+	}
+	switch {
+	case value == n.Value:
+		n.Data = data
+		return n
+	case value < n.Value:
+		n.Left = n.Left.insert(value, data)
+	default:
+		n.Right = n.Right.insert(value, data)
+	}
+	return n.rebalance()
+}
+
+// `Insert` inserts a value or updates the data of an existing one.
+func (t *WBTree) Insert(value, data string) {
+	t.Root = t.Root.insert(value, data)
+}
+
+// `Find` returns the data stored for `value`.
+func (t *WBTree) Find(value string) (string, bool) {
+	n := t.Root
+	for n != nil {
+		switch {
+		case value == n.Value:
+			return n.Data, true
+		case value < n.Value:
+			n = n.Left
+		default:
+			n = n.Right
+		}
+	}
+	return "", false
+}
+
+// `Len` returns the number of values in the tree.
+func (t *WBTree) Len() int {
+	return t.Root.Size()
+}
+
+// `Rank` returns the number of values in the tree that are smaller than `value`.
+// If `value` is in the tree, this is its zero-based position in sort order.
+func (t *WBTree) Rank(value string) int {
+	rank := 0
+	n := t.Root
+	for n != nil {
+		switch {
+		case value == n.Value:
+			return rank + n.Left.Size()
+		case value < n.Value:
+			n = n.Left
+		default:
+			rank += n.Left.Size() + 1
+			n = n.Right
+		}
+	}
+	return rank
+}
+
+// `Select` returns the value and data at zero-based position `i` in sort order.
+func (t *WBTree) Select(i int) (value, data string, ok bool) {
+	n := t.Root
+	for n != nil {
+		l := n.Left.Size()
+		switch {
+		case i == l:
+			return n.Value, n.Data, true
+		case i < l:
+			n = n.Left
+		default:
+			i -= l + 1
+			n = n.Right
+		}
+	}
+	return "", "", false
+}
+
+// `Walk` calls `f` for each value and its data in sort order.
+func (t *WBTree) Walk(f func(value, data string)) {
+	var walk func(*WBNode)
+	walk = func(n *WBNode) {
+		if n == nil {
+			return
+		}
+		walk(n.Left)
+		f(n.Value, n.Data)
+		walk(n.Right)
+	}
+	walk(t.Root)
+}
+
+// `Dump` prints the tree like `Tree.Dump`, but with subtree sizes.
+func (t *WBTree) Dump() {
+	dumpTree(os.Stdout, t.Root, 0, "", func(n *WBNode) (string, *WBNode, *WBNode) {
+		return fmt.Sprintf("%s[%d]", n.Value, n.size), n.Left, n.Right
+	})
+}
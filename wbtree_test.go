@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// checkWeights verifies sizes, weight balance, and order of the subtree at n.
+func (n *WBNode) checkWeights(tb testing.TB, lo, hi *string) int {
+	if n == nil {
+		return 0
+	}
+	if (lo != nil && n.Value <= *lo) || (hi != nil && n.Value >= *hi) {
+		tb.Fatalf("node %s is out of order", n.Value)
+	}
+	ls, rs := n.Left.checkWeights(tb, lo, &n.Value), n.Right.checkWeights(tb, &n.Value, hi)
+	if n.size != ls+rs+1 {
+		tb.Fatalf("node %s has size %d, want %d", n.Value, n.size, ls+rs+1)
+	}
+	if (ls+1)*wbDelta < rs+1 || (rs+1)*wbDelta < ls+1 {
+		tb.Fatalf("node %s is out of balance: sizes %d and %d", n.Value, ls, rs)
+	}
+	return n.size
+}
+
+func TestWBTree_Rank(t *testing.T) {
+	wb := &WBTree{}
+	seen := map[string]bool{}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		k := strconv.Itoa(r.Intn(1000))
+		wb.Insert(k, k)
+		seen[k] = true
+	}
+	wb.Root.checkWeights(t, nil, nil)
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if wb.Len() != len(keys) {
+		t.Fatalf("Len() = %d, want %d", wb.Len(), len(keys))
+	}
+	for i, k := range keys {
+		if rank := wb.Rank(k); rank != i {
+			t.Fatalf("Rank(%s) = %d, want %d", k, rank, i)
+		}
+		if v, d, ok := wb.Select(i); !ok || v != k || d != k {
+			t.Fatalf("Select(%d) = %s, %s, %t; want %s", i, v, d, ok, k)
+		}
+	}
+	if rank := wb.Rank(keys[len(keys)-1] + "x"); rank != len(keys) {
+		t.Errorf("Rank of a value beyond the last one is %d, want %d", rank, len(keys))
+	}
+	if _, _, ok := wb.Select(len(keys)); ok {
+		t.Errorf("Select beyond the last position succeeded")
+	}
+}